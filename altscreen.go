@@ -0,0 +1,103 @@
+package probe
+
+import (
+	"sync"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Sequences that switch to and from the alternate screen when terminfo does not say otherwise.
+const (
+	altScreenEnter = "\x1b[?1049h" // Save the cursor, switch to the alternate screen and clear it
+	altScreenExit  = "\x1b[?1049l" // Switch back to the main screen and restore the cursor
+)
+
+// altScreen tracks how many callers are using the alternate screen of a terminal.
+type altScreen struct {
+	count int    // Number of EnterAltScreen calls not yet matched by ExitAltScreen
+	exit  string // Sequence that leaves the alternate screen, captured on entry
+	stop  func() // Stops the signal handler installed on entry, if any
+}
+
+// altScreens holds the alternate screen state of each file descriptor.
+var altScreens = struct {
+	fds   map[uintptr]*altScreen // Maps file descriptors to their alternate screen state
+	mutex sync.Mutex             // Protects concurrent access to the map
+}{
+	fds: make(map[uintptr]*altScreen),
+}
+
+// EnterAltScreen switches the terminal to the alternate screen, as full-screen programs do,
+// so that the user's scrollback is left untouched and reappears when the program is done.
+// It uses the terminal's smcup and rmcup terminfo capabilities when an entry for $TERM is found,
+// does nothing for terminal types whose entry has none, and otherwise uses DECSET 1049.
+//
+// Calls are reference-counted: nested components may each call EnterAltScreen and ExitAltScreen,
+// and the main screen only comes back when the last of them exits. With RestoreOnSignal, the terminal also
+// returns to the main screen if the process receives a termination signal while the alternate screen is in use.
+// Nothing is written when the file descriptor is not a terminal.
+func EnterAltScreen(fd uintptr) error {
+	return enterAltScreen(fd, true)
+}
+
+// ExitAltScreen undoes one call to EnterAltScreen, switching back to the main screen
+// when no other caller is still using the alternate screen. Extra calls do nothing.
+func ExitAltScreen(fd uintptr) error {
+	if !IsTerminal(fd) {
+		return nil
+	}
+
+	altScreens.mutex.Lock()
+	defer altScreens.mutex.Unlock()
+
+	screen, ok := altScreens.fds[fd]
+	if !ok {
+		return nil
+	}
+	screen.count--
+	if screen.count > 0 {
+		return nil
+	}
+
+	delete(altScreens.fds, fd)
+	if screen.stop != nil {
+		screen.stop()
+	}
+	_, err := platform.Write(fd, []byte(screen.exit))
+	return err
+}
+
+// enterAltScreen implements EnterAltScreen. When protect is true and the terminal switches screens,
+// a signal handler is installed to switch back; a Session does without because it handles signals itself.
+func enterAltScreen(fd uintptr, protect bool) error {
+	if !IsTerminal(fd) {
+		return nil
+	}
+
+	altScreens.mutex.Lock()
+	defer altScreens.mutex.Unlock()
+
+	if screen, ok := altScreens.fds[fd]; ok {
+		screen.count++
+		return nil
+	}
+
+	enter, exit := altScreenEnter, altScreenExit
+	if ti, err := loadTerminfo(); err == nil {
+		enter, exit = ti.str(capSmcup), ti.str(capRmcup)
+	}
+	if enter != "" {
+		if _, err := platform.Write(fd, []byte(enter)); err != nil {
+			return err
+		}
+	}
+
+	screen := &altScreen{count: 1, exit: exit}
+	if protect && exit != "" {
+		screen.stop = restoreOnSignal(func() {
+			_, _ = platform.Write(fd, []byte(exit))
+		})
+	}
+	altScreens.fds[fd] = screen
+	return nil
+}
@@ -0,0 +1,76 @@
+package probe
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// TerminalAttributes is a read-only snapshot of the line discipline settings of a terminal.
+// It covers echo, canonical mode, signal generation, flow control, output processing,
+// baud rates and the most common control characters. It can be encoded as JSON.
+type TerminalAttributes platform.Attributes
+
+// ControlChar is a special input character of the line discipline, such as the interrupt character.
+// The zero value means the character is disabled.
+type ControlChar = platform.ControlChar
+
+// Attributes returns a snapshot of the line discipline settings of the terminal.
+// It returns a *NotTerminalError if the file descriptor is not a terminal.
+// The result is not cached because the settings change whenever a program switches modes.
+func Attributes(fd uintptr) (*TerminalAttributes, error) {
+	if !IsTerminal(fd) {
+		return nil, &NotTerminalError{Fd: fd}
+	}
+
+	attrs, err := platform.GetAttributes(fd)
+	if err != nil {
+		return nil, err
+	}
+	return (*TerminalAttributes)(attrs), nil
+}
+
+// String formats the attributes in the style of stty, for example:
+//
+//	speed 38400 baud; echo icanon isig ixon opost onlcr; intr = ^C; eof = ^D; erase = ^?
+//
+// Disabled flags are prefixed with a dash.
+func (a *TerminalAttributes) String() string {
+	var b strings.Builder
+
+	switch {
+	case a.OutputSpeed == 0:
+		b.WriteString("speed unknown")
+	case a.InputSpeed != 0 && a.InputSpeed != a.OutputSpeed:
+		b.WriteString("ispeed " + strconv.FormatUint(uint64(a.InputSpeed), 10) + " baud; ")
+		b.WriteString("ospeed " + strconv.FormatUint(uint64(a.OutputSpeed), 10) + " baud")
+	default:
+		b.WriteString("speed " + strconv.FormatUint(uint64(a.OutputSpeed), 10) + " baud")
+	}
+
+	b.WriteString(";")
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"echo", a.Echo},
+		{"icanon", a.Canonical},
+		{"isig", a.Signals},
+		{"ixon", a.FlowControl},
+		{"opost", a.OutputProcessing},
+		{"onlcr", a.MapNewline},
+	}
+	for _, flag := range flags {
+		b.WriteString(" ")
+		if !flag.set {
+			b.WriteString("-")
+		}
+		b.WriteString(flag.name)
+	}
+
+	b.WriteString("; intr = " + a.Interrupt.String())
+	b.WriteString("; eof = " + a.EOF.String())
+	b.WriteString("; erase = " + a.Erase.String())
+	return b.String()
+}
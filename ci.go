@@ -0,0 +1,180 @@
+package probe
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// CIProvider identifies a continuous integration service.
+type CIProvider string
+
+// These constants enumerate the CI services recognized by DetectCI.
+const (
+	CIGitHubActions  CIProvider = "github-actions"
+	CIGitLab         CIProvider = "gitlab"
+	CIBuildkite      CIProvider = "buildkite"
+	CIJenkins        CIProvider = "jenkins"
+	CICircleCI       CIProvider = "circleci"
+	CIAzurePipelines CIProvider = "azure-pipelines"
+	CITeamCity       CIProvider = "teamcity"
+	CITravis         CIProvider = "travis"
+	CIDrone          CIProvider = "drone"
+	CIGeneric        CIProvider = "generic" // A service that only sets CI, as most do
+)
+
+// CI describes the continuous integration build the process runs in.
+// Metadata the service does not provide is left empty.
+type CI struct {
+	Provider    CIProvider `json:"provider"`     // The CI service
+	ANSIColor   bool       `json:"ansi_color"`   // Whether the build log renders ANSI colors although output is not a terminal
+	PullRequest string     `json:"pull_request"` // Number or id of the pull or merge request being built, or empty for other builds
+	Branch      string     `json:"branch"`       // Branch being built; for pull requests, the source branch where known
+	Commit      string     `json:"commit"`       // Commit being built
+	Repository  string     `json:"repository"`   // Repository, as a path such as "owner/name" or as a URL, depending on the service
+	BuildID     string     `json:"build_id"`     // Identifier or number of the build
+	BuildURL    string     `json:"build_url"`    // Web page of the build
+}
+
+// DetectCI reports whether the process runs in a continuous integration build, and which, from the
+// environment variables the services set. Output in CI is usually not a terminal, so IsTerminal reports false
+// even where the build log renders colors; a program can decide to use them with:
+//
+//	ci, _ := probe.DetectCI()
+//	color := probe.IsTerminal(os.Stdout.Fd()) || ci.ANSIColor
+//
+// Jenkins only renders colors with the AnsiColor plugin and unknown services may not at all,
+// so ANSIColor is false for them. The result is not cached because it depends on the environment.
+func DetectCI() (ci CI, ok bool) {
+	env := os.Getenv
+	switch {
+	case env("GITHUB_ACTIONS") == "true":
+		ci = CI{
+			Provider:   CIGitHubActions,
+			ANSIColor:  true,
+			Branch:     firstOf(env("GITHUB_HEAD_REF"), env("GITHUB_REF_NAME")),
+			Commit:     env("GITHUB_SHA"),
+			Repository: env("GITHUB_REPOSITORY"),
+			BuildID:    env("GITHUB_RUN_ID"),
+		}
+		// Pull request builds check out "refs/pull/<number>/merge".
+		if number, ok := strings.CutPrefix(env("GITHUB_REF"), "refs/pull/"); ok {
+			ci.PullRequest, _, _ = strings.Cut(number, "/")
+		}
+		if server := env("GITHUB_SERVER_URL"); server != "" && ci.Repository != "" && ci.BuildID != "" {
+			ci.BuildURL = server + "/" + ci.Repository + "/actions/runs/" + ci.BuildID
+		}
+	case env("GITLAB_CI") != "":
+		ci = CI{
+			Provider:    CIGitLab,
+			ANSIColor:   true,
+			PullRequest: env("CI_MERGE_REQUEST_IID"),
+			Branch:      firstOf(env("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"), env("CI_COMMIT_REF_NAME")),
+			Commit:      env("CI_COMMIT_SHA"),
+			Repository:  env("CI_PROJECT_PATH"),
+			BuildID:     env("CI_JOB_ID"),
+			BuildURL:    env("CI_JOB_URL"),
+		}
+	case env("BUILDKITE") == "true":
+		ci = CI{
+			Provider:    CIBuildkite,
+			ANSIColor:   true,
+			PullRequest: notFalse(env("BUILDKITE_PULL_REQUEST")),
+			Branch:      env("BUILDKITE_BRANCH"),
+			Commit:      env("BUILDKITE_COMMIT"),
+			Repository:  env("BUILDKITE_REPO"),
+			BuildID:     env("BUILDKITE_BUILD_NUMBER"),
+			BuildURL:    env("BUILDKITE_BUILD_URL"),
+		}
+	case env("CIRCLECI") == "true":
+		ci = CI{
+			Provider:   CICircleCI,
+			ANSIColor:  true,
+			Branch:     env("CIRCLE_BRANCH"),
+			Commit:     env("CIRCLE_SHA1"),
+			Repository: strings.Trim(env("CIRCLE_PROJECT_USERNAME")+"/"+env("CIRCLE_PROJECT_REPONAME"), "/"),
+			BuildID:    env("CIRCLE_BUILD_NUM"),
+			BuildURL:   env("CIRCLE_BUILD_URL"),
+		}
+		// CIRCLE_PULL_REQUEST holds the URL of the pull request, which ends with its number.
+		if url := env("CIRCLE_PULL_REQUEST"); url != "" {
+			ci.PullRequest = firstOf(env("CIRCLE_PR_NUMBER"), path.Base(url))
+		}
+	case env("TF_BUILD") != "":
+		ci = CI{
+			Provider:    CIAzurePipelines,
+			ANSIColor:   true,
+			PullRequest: firstOf(env("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER"), env("SYSTEM_PULLREQUEST_PULLREQUESTID")),
+			Branch:      firstOf(strings.TrimPrefix(env("SYSTEM_PULLREQUEST_SOURCEBRANCH"), "refs/heads/"), env("BUILD_SOURCEBRANCHNAME")),
+			Commit:      env("BUILD_SOURCEVERSION"),
+			Repository:  env("BUILD_REPOSITORY_NAME"),
+			BuildID:     env("BUILD_BUILDID"),
+		}
+		if collection, project := env("SYSTEM_COLLECTIONURI"), env("SYSTEM_TEAMPROJECT"); collection != "" && project != "" && ci.BuildID != "" {
+			ci.BuildURL = strings.TrimSuffix(collection, "/") + "/" + project + "/_build/results?buildId=" + ci.BuildID
+		}
+	case env("TEAMCITY_VERSION") != "":
+		ci = CI{
+			Provider:  CITeamCity,
+			ANSIColor: true,
+			Commit:    env("BUILD_VCS_NUMBER"),
+			BuildID:   env("BUILD_NUMBER"),
+		}
+	case env("TRAVIS") == "true":
+		ci = CI{
+			Provider:    CITravis,
+			ANSIColor:   true,
+			PullRequest: notFalse(env("TRAVIS_PULL_REQUEST")),
+			Branch:      firstOf(env("TRAVIS_PULL_REQUEST_BRANCH"), env("TRAVIS_BRANCH")),
+			Commit:      env("TRAVIS_COMMIT"),
+			Repository:  env("TRAVIS_REPO_SLUG"),
+			BuildID:     env("TRAVIS_BUILD_ID"),
+			BuildURL:    env("TRAVIS_BUILD_WEB_URL"),
+		}
+	case env("DRONE") == "true":
+		ci = CI{
+			Provider:    CIDrone,
+			ANSIColor:   true,
+			PullRequest: env("DRONE_PULL_REQUEST"),
+			Branch:      firstOf(env("DRONE_SOURCE_BRANCH"), env("DRONE_BRANCH")),
+			Commit:      env("DRONE_COMMIT_SHA"),
+			Repository:  env("DRONE_REPO"),
+			BuildID:     env("DRONE_BUILD_NUMBER"),
+			BuildURL:    env("DRONE_BUILD_LINK"),
+		}
+	case env("JENKINS_URL") != "" && env("BUILD_ID") != "":
+		ci = CI{
+			Provider:    CIJenkins,
+			PullRequest: env("CHANGE_ID"),
+			Branch:      firstOf(env("CHANGE_BRANCH"), env("BRANCH_NAME"), env("GIT_BRANCH")),
+			Commit:      env("GIT_COMMIT"),
+			Repository:  env("GIT_URL"),
+			BuildID:     env("BUILD_NUMBER"),
+			BuildURL:    env("BUILD_URL"),
+		}
+	default:
+		if value := env("CI"); value == "" || value == "false" || value == "0" {
+			return CI{}, false
+		}
+		ci = CI{Provider: CIGeneric}
+	}
+	return ci, true
+}
+
+// firstOf returns the first of values that is not empty.
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// notFalse returns value, or an empty string if it is "false", as services set for builds that are not pull requests.
+func notFalse(value string) string {
+	if value == "false" {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,296 @@
+package probe
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnnotationLevel is the severity of an annotation written by CIWriter.
+type AnnotationLevel int
+
+// These constants enumerate the annotation levels.
+const (
+	AnnotationError   AnnotationLevel = iota // A problem that fails the build
+	AnnotationWarning                        // A problem that does not fail the build
+	AnnotationNotice                         // Information worth highlighting
+)
+
+// String returns the lowercase name of the level, such as "error".
+func (l AnnotationLevel) String() string {
+	switch l {
+	case AnnotationWarning:
+		return "warning"
+	case AnnotationNotice:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// Annotation is a message attached to the build, and to a place in the source where the service supports it.
+type Annotation struct {
+	Level   AnnotationLevel // Severity of the annotation
+	Message string          // Text of the annotation, which may span several lines
+	Title   string          // Short summary shown above the message, where supported
+	File    string          // Path of the file the annotation refers to, relative to the repository, or empty
+	Line    int             // Line in the file, starting at 1, or zero
+	Column  int             // Column in the line, starting at 1, or zero
+}
+
+// CIWriter writes build output with collapsible log groups and annotations in the native syntax of a CI service:
+// workflow commands on GitHub Actions, section markers on GitLab CI, "---" headers on Buildkite,
+// logging commands on Azure Pipelines, service messages on TeamCity and fold markers on Travis CI.
+// For other services, and outside CI, group names and annotations are written as plain text.
+//
+// Writes that are not groups or annotations are passed through unchanged. A CIWriter is safe for concurrent use.
+type CIWriter struct {
+	w        io.Writer  // Destination of the output
+	provider CIProvider // Service whose syntax is used, or empty for plain text
+
+	mutex  sync.Mutex // Protects the fields below and keeps lines from interleaving
+	groups []string   // Names or section ids of the open groups, innermost last
+	serial int        // Number of groups started, for unique section ids
+}
+
+// NewCIWriter returns a CIWriter that writes to w in the syntax of provider.
+// Pass the provider returned by DetectCI, or an empty provider for plain text:
+//
+//	ci, _ := probe.DetectCI()
+//	log := probe.NewCIWriter(os.Stdout, ci.Provider)
+func NewCIWriter(w io.Writer, provider CIProvider) *CIWriter {
+	return &CIWriter{w: w, provider: provider}
+}
+
+// Write writes p unchanged.
+func (c *CIWriter) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.w.Write(p)
+}
+
+// StartGroup starts a collapsible group of log lines with the given name, until EndGroup is called.
+// Groups nest on GitLab CI and TeamCity; elsewhere a group should be ended before the next starts.
+func (c *CIWriter) StartGroup(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	name = strings.Map(dropNewline, name)
+	c.serial++
+	id := name
+	var line string
+	switch c.provider {
+	case CIGitHubActions:
+		line = "::group::" + name + "\n"
+	case CIGitLab:
+		id = sectionID(name, c.serial)
+		line = "\x1b[0Ksection_start:" + timestamp() + ":" + id + "[collapsed=true]\r\x1b[0K" + name + "\n"
+	case CIBuildkite:
+		line = "--- " + name + "\n"
+	case CIAzurePipelines:
+		line = "##[group]" + name + "\n"
+	case CITeamCity:
+		line = "##teamcity[blockOpened name='" + teamCityEscape(name) + "']\n"
+	case CITravis:
+		id = sectionID(name, c.serial)
+		line = "travis_fold:start:" + id + "\r\x1b[0K" + name + "\n"
+	default:
+		line = name + "\n"
+	}
+
+	c.groups = append(c.groups, id)
+	return c.writeString(line)
+}
+
+// EndGroup ends the innermost group started by StartGroup. It does nothing if no group is open.
+func (c *CIWriter) EndGroup() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.groups) == 0 {
+		return nil
+	}
+	id := c.groups[len(c.groups)-1]
+	c.groups = c.groups[:len(c.groups)-1]
+
+	switch c.provider {
+	case CIGitHubActions:
+		return c.writeString("::endgroup::\n")
+	case CIGitLab:
+		return c.writeString("\x1b[0Ksection_end:" + timestamp() + ":" + id + "\r\x1b[0K\n")
+	case CIAzurePipelines:
+		return c.writeString("##[endgroup]\n")
+	case CITeamCity:
+		return c.writeString("##teamcity[blockClosed name='" + teamCityEscape(id) + "']\n")
+	case CITravis:
+		return c.writeString("travis_fold:end:" + id + "\r\x1b[0K")
+	}
+	// Buildkite groups end where the next starts, and plain text has nothing to close.
+	return nil
+}
+
+// Group runs fn inside a group with the given name, ending the group even if fn fails, and returns fn's error.
+func (c *CIWriter) Group(name string, fn func() error) error {
+	if err := c.StartGroup(name); err != nil {
+		return err
+	}
+	err := fn()
+	if endErr := c.EndGroup(); err == nil {
+		err = endErr
+	}
+	return err
+}
+
+// Annotate writes an annotation. GitHub Actions and Azure Pipelines show it on the build summary and,
+// when File is set, next to the source; TeamCity marks the build log message with its status.
+// Buildkite expands the current group so that the annotation is visible. Elsewhere it is written as
+// a line such as "error: main.go:12:5: message".
+func (c *CIWriter) Annotate(a Annotation) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var line string
+	switch c.provider {
+	case CIGitHubActions:
+		var props []string
+		for _, p := range []struct{ key, value string }{
+			{"file", a.File}, {"line", itoaPositive(a.Line)}, {"col", itoaPositive(a.Column)}, {"title", a.Title},
+		} {
+			if p.value != "" {
+				props = append(props, p.key+"="+gitHubEscape(p.value, true))
+			}
+		}
+		command := "::" + a.Level.String()
+		if len(props) > 0 {
+			command += " " + strings.Join(props, ",")
+		}
+		line = command + "::" + gitHubEscape(a.Message, false) + "\n"
+	case CIAzurePipelines:
+		// Azure Pipelines has no notice issues, so notices are plain lines.
+		if a.Level == AnnotationNotice {
+			line = plainAnnotation(a)
+			break
+		}
+		props := []string{"type=" + a.Level.String()}
+		for _, p := range []struct{ key, value string }{
+			{"sourcepath", a.File}, {"linenumber", itoaPositive(a.Line)}, {"columnnumber", itoaPositive(a.Column)},
+		} {
+			if p.value != "" {
+				props = append(props, p.key+"="+azureEscape(p.value, true))
+			}
+		}
+		line = "##vso[task.logissue " + strings.Join(props, ";") + "]" + azureEscape(a.Message, false) + "\n"
+	case CITeamCity:
+		status := map[AnnotationLevel]string{AnnotationError: "ERROR", AnnotationWarning: "WARNING", AnnotationNotice: "NORMAL"}[a.Level]
+		line = "##teamcity[message text='" + teamCityEscape(locate(a)+a.Message) + "' status='" + status + "']\n"
+	case CIBuildkite:
+		line = plainAnnotation(a)
+		if a.Level != AnnotationNotice {
+			line = "^^^ +++\n" + line
+		}
+	default:
+		line = plainAnnotation(a)
+	}
+	return c.writeString(line)
+}
+
+// Error writes an error annotation with the given message.
+func (c *CIWriter) Error(message string) error {
+	return c.Annotate(Annotation{Level: AnnotationError, Message: message})
+}
+
+// Warning writes a warning annotation with the given message.
+func (c *CIWriter) Warning(message string) error {
+	return c.Annotate(Annotation{Level: AnnotationWarning, Message: message})
+}
+
+// writeString writes s in a single call. The caller must hold the mutex.
+func (c *CIWriter) writeString(s string) error {
+	_, err := io.WriteString(c.w, s)
+	return err
+}
+
+// timestamp returns the current Unix time, as GitLab section markers need.
+func timestamp() string {
+	return strconv.FormatInt(time.Now().Unix(), 10)
+}
+
+// plainAnnotation formats an annotation as a line of plain text, such as "warning: main.go:12: title: message".
+func plainAnnotation(a Annotation) string {
+	line := a.Level.String() + ": " + locate(a)
+	if a.Title != "" {
+		line += a.Title + ": "
+	}
+	return line + a.Message + "\n"
+}
+
+// locate returns the place an annotation refers to as "file:line:column: ", omitting what is not set.
+func locate(a Annotation) string {
+	if a.File == "" {
+		return ""
+	}
+	location := a.File
+	if a.Line > 0 {
+		location += ":" + strconv.Itoa(a.Line)
+		if a.Column > 0 {
+			location += ":" + strconv.Itoa(a.Column)
+		}
+	}
+	return location + ": "
+}
+
+// sectionID derives an identifier for GitLab and Travis CI sections from a group name,
+// keeping the characters they allow and adding n to keep it unique.
+func sectionID(name string, n int) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		case r == ' ':
+			return '_'
+		}
+		return -1
+	}, name)
+	return strings.ToLower(id) + "_" + strconv.Itoa(n)
+}
+
+// gitHubEscape escapes the data of a GitHub Actions workflow command, or a property value if property is set.
+func gitHubEscape(s string, property bool) string {
+	s = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+	if property {
+		s = strings.NewReplacer(":", "%3A", ",", "%2C").Replace(s)
+	}
+	return s
+}
+
+// azureEscape escapes the message of an Azure Pipelines logging command, or a property value if property is set.
+func azureEscape(s string, property bool) string {
+	s = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(s)
+	if property {
+		s = strings.NewReplacer(";", "%3B", "]", "%5D").Replace(s)
+	}
+	return s
+}
+
+// teamCityEscape escapes a value in a TeamCity service message.
+func teamCityEscape(s string) string {
+	return strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]").Replace(s)
+}
+
+// itoaPositive formats n, or returns an empty string if it is not positive.
+func itoaPositive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// dropNewline replaces line breaks, which would end a group header early, with spaces, for use with strings.Map.
+func dropNewline(r rune) rune {
+	if r == '\n' || r == '\r' {
+		return ' '
+	}
+	return r
+}
@@ -0,0 +1,125 @@
+package probe
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Selection identifies a clipboard of the terminal's host. Its values are the OSC 52 selection parameters.
+type Selection byte
+
+// These constants enumerate the selections.
+const (
+	SelectionClipboard Selection = 'c' // The system clipboard, used by copy and paste
+	SelectionPrimary   Selection = 'p' // The X11 primary selection, pasted with the middle mouse button
+	SelectionSecondary Selection = 'q' // The rarely used X11 secondary selection
+)
+
+// defaultClipboardLimit is the most data written in one OSC 52 sequence to emulators without a known limit.
+// Encoded in base64 it just fits the 100000 bytes accepted by hterm and several other emulators.
+const defaultClipboardLimit = 74994
+
+// clipboardLimits maps emulators to the most data they accept in one OSC 52 sequence.
+// Zero means no practical limit, and a negative value means OSC 52 is not supported at all.
+var clipboardLimits = map[string]int{
+	emulatorKitty:         0,
+	emulatorWezTerm:       0,
+	emulatorAlacritty:     0,
+	emulatorFoot:          0,
+	emulatorGhostty:       0,
+	emulatorAppleTerminal: -1,
+	emulatorVTE:           -1,
+	emulatorLinux:         -1,
+	emulatorDumb:          -1,
+	emulatorEmacs:         -1,
+	emulatorJediTerm:      -1,
+}
+
+// SetClipboard copies data to a selection of the host running the terminal emulator with OSC 52,
+// which also works from a remote machine over SSH. Inside tmux or GNU screen the sequence is wrapped
+// so that it reaches the outer terminal; tmux must have allow-passthrough or set-clipboard enabled.
+// The terminal gives no confirmation, so a nil error does not guarantee that the clipboard changed.
+// It returns ErrClipboardUnavailable if the emulator is known not to support OSC 52,
+// ErrClipboardTooLarge if data exceeds its known limit, and a *NotTerminalError if fd is not a terminal.
+func SetClipboard(fd uintptr, selection Selection, data []byte) error {
+	if !IsTerminal(fd) {
+		return &NotTerminalError{Fd: fd}
+	}
+
+	e := detectEmulator()
+	limit, ok := clipboardLimits[e.name]
+	if !ok {
+		limit = defaultClipboardLimit
+	}
+	switch {
+	case limit < 0:
+		return ErrClipboardUnavailable
+	case limit > 0 && len(data) > limit:
+		return ErrClipboardTooLarge
+	}
+
+	seq := clipboardSequence(selection, base64.StdEncoding.EncodeToString(data), e.multiplexer)
+	_, err := platform.Write(fd, []byte(passthrough(seq, e.multiplexer)))
+	return err
+}
+
+// GetClipboard reads the system clipboard of the host running the terminal emulator with OSC 52.
+// Most emulators disable reading by default or ask the user first, so callers should expect
+// ErrClipboardUnavailable, which is returned when the terminal answers the DA1 sentinel but not the request.
+// If ctx has no deadline, DefaultQueryTimeout applies; when it expires the error wraps both
+// ErrClipboardUnavailable and ErrNoReply. Allow more time where the user may be prompted.
+// The file descriptor must be readable and writable; a *NotTerminalError is returned otherwise.
+func GetClipboard(ctx context.Context, fd uintptr) ([]byte, error) {
+	if !IsTerminal(fd) {
+		return nil, &NotTerminalError{Fd: fd}
+	}
+
+	e := detectEmulator()
+	if limit, ok := clipboardLimits[e.name]; ok && limit < 0 {
+		return nil, ErrClipboardUnavailable
+	}
+
+	request := clipboardSequence(SelectionClipboard, "?", e.multiplexer) + da1Request
+	replies, _, err := exchange(ctx, fd, passthrough(request, e.multiplexer))
+	if errors.Is(err, ErrNoReply) {
+		return nil, fmt.Errorf("%w: %w", ErrClipboardUnavailable, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reply := range replies {
+		if reply.intro != ']' || !reply.hasPrefix("52;") {
+			continue
+		}
+		// The reply is "52 ; selection ; data", where the selection may be empty.
+		_, data, ok := strings.Cut(reply.body[len("52;"):], ";")
+		if !ok {
+			continue
+		}
+		if data == "?" {
+			return nil, ErrClipboardUnavailable
+		}
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("probe: invalid clipboard reply: %w", err)
+		}
+		return decoded, nil
+	}
+	return nil, ErrClipboardUnavailable
+}
+
+// clipboardSequence returns an OSC 52 sequence for the selection with the given payload.
+// It ends with BEL inside GNU screen, whose passthrough cannot carry the usual string terminator.
+func clipboardSequence(selection Selection, payload, multiplexer string) string {
+	end := "\x1b\\"
+	if multiplexer == multiplexerScreen {
+		end = "\a"
+	}
+	return "\x1b]52;" + string(selection) + ";" + payload + end
+}
@@ -0,0 +1,194 @@
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Kind describes the type of device behind a terminal file descriptor.
+type Kind int
+
+// These constants enumerate the terminal device kinds reported by DeviceKind.
+const (
+	KindUnknown        Kind = iota // The device could not be classified
+	KindPTY                        // A pseudo-terminal, as used by terminal emulators and SSH
+	KindSerial                     // A hardware serial line, such as /dev/ttyS0
+	KindVirtualConsole             // A kernel virtual console, such as /dev/tty1
+	KindUSBSerial                  // A USB serial adapter, such as /dev/ttyUSB0 or /dev/ttyACM0
+)
+
+// defaultSysfsRoot is where sysfs is mounted on Linux.
+const defaultSysfsRoot = "/sys"
+
+// String returns a short lowercase name for the device kind.
+func (k Kind) String() string {
+	switch k {
+	case KindPTY:
+		return "pty"
+	case KindSerial:
+		return "serial"
+	case KindVirtualConsole:
+		return "virtual-console"
+	case KindUSBSerial:
+		return "usb-serial"
+	default:
+		return "unknown"
+	}
+}
+
+// DeviceOption configures DeviceKind and ClassifyDevice.
+type DeviceOption func(*deviceOptions)
+
+// deviceOptions holds the settings applied by DeviceOption values.
+type deviceOptions struct {
+	sysfsRoot string // Directory where sysfs is mounted
+}
+
+// WithSysfsRoot reads device metadata from root instead of /sys.
+// It is mainly useful for testing against a fake sysfs tree.
+func WithSysfsRoot(root string) DeviceOption {
+	return func(o *deviceOptions) {
+		o.sysfsRoot = root
+	}
+}
+
+// DeviceKind returns the kind of terminal device behind the file descriptor.
+// On Linux the kind is derived from the device major number and /sys/class/tty metadata;
+// on other platforms it is inferred from the device name returned by TerminalName.
+// It returns KindUnknown if the file descriptor is not a terminal.
+func DeviceKind(fd uintptr, opts ...DeviceOption) Kind {
+	if !IsTerminal(fd) {
+		return KindUnknown
+	}
+
+	name, _ := TerminalName(fd)
+	if runtime.GOOS == "linux" || runtime.GOOS == "android" {
+		if major, minor, err := platform.DeviceNumber(fd); err == nil {
+			return ClassifyDevice(name, major, minor, opts...)
+		}
+	}
+	return classifyName(name)
+}
+
+// ClassifyDevice returns the kind of a Linux terminal device given its name and device numbers.
+// The name may be a path such as /dev/ttyUSB0 or a bare name such as ttyUSB0, and may be empty.
+// Well-known major numbers are checked first, then sysfs, and finally the device name.
+func ClassifyDevice(name string, major, minor uint32, opts ...DeviceOption) Kind {
+	o := deviceOptions{sysfsRoot: defaultSysfsRoot}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if kind := classifyMajor(major, minor); kind != KindUnknown {
+		return kind
+	}
+	if kind := classifySysfs(o.sysfsRoot, filepath.Base(name), major, minor); kind != KindUnknown {
+		return kind
+	}
+	return classifyName(name)
+}
+
+// classifyMajor classifies a device using the statically allocated Linux major numbers.
+// See Documentation/admin-guide/devices.txt in the Linux source tree.
+func classifyMajor(major, minor uint32) Kind {
+	switch {
+	case major == 2 || major == 3:
+		return KindPTY // BSD-style pty masters and slaves
+	case major == 4 && minor < 64:
+		return KindVirtualConsole // tty0 to tty63
+	case major == 4:
+		return KindSerial // ttyS0 and up
+	case major == 5 && minor == 2:
+		return KindPTY // ptmx
+	case major >= 128 && major <= 143:
+		return KindPTY // Unix98 pty masters and slaves
+	case major == 166 || major == 188:
+		return KindUSBSerial // ttyACM and ttyUSB
+	case major == 204:
+		return KindSerial // Low-density serial ports, such as ttyAMA
+	}
+	return KindUnknown
+}
+
+// classifySysfs classifies a device from its entry in /sys/class/tty.
+// If name is empty, it is resolved through /sys/dev/char/<major>:<minor>.
+func classifySysfs(root, name string, major, minor uint32) Kind {
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		target, err := filepath.EvalSymlinks(filepath.Join(root, "dev", "char",
+			strconv.FormatUint(uint64(major), 10)+":"+strconv.FormatUint(uint64(minor), 10)))
+		if err != nil {
+			return KindUnknown
+		}
+		name = filepath.Base(target)
+	}
+
+	class := filepath.Join(root, "class", "tty", name)
+	device, err := filepath.EvalSymlinks(filepath.Join(class, "device"))
+	if err != nil {
+		// Entries without a backing device are virtual, such as tty1 or console.
+		if _, err := os.Stat(class); err == nil && isVirtualConsoleName(name) {
+			return KindVirtualConsole
+		}
+		return KindUnknown
+	}
+
+	if subsystem, err := filepath.EvalSymlinks(filepath.Join(device, "subsystem")); err == nil {
+		switch filepath.Base(subsystem) {
+		case "usb", "usb-serial":
+			return KindUSBSerial
+		}
+	}
+	if strings.Contains(filepath.ToSlash(device), "/usb") {
+		return KindUSBSerial
+	}
+
+	// A tty backed by a hardware device that is not on USB is a serial port.
+	return KindSerial
+}
+
+// classifyName classifies a device from its path using naming conventions across platforms.
+func classifyName(name string) Kind {
+	if name == "" {
+		return KindUnknown
+	}
+
+	base := filepath.Base(name)
+	switch {
+	case strings.Contains(filepath.ToSlash(name), "/pts/"):
+		return KindPTY
+	case hasAnyPrefix(base, "ttyUSB", "ttyACM", "ttyU", "cuaU", "tty.usb", "cu.usb"):
+		return KindUSBSerial
+	case hasAnyPrefix(base, "ttyS", "ttyAMA", "ttySAC", "ttymxc", "ttyO", "ttyu", "cuau", "cua"):
+		return KindSerial
+	case isVirtualConsoleName(base) || hasAnyPrefix(base, "ttyv", "ttyC", "ttyE"):
+		return KindVirtualConsole
+	case hasAnyPrefix(base, "ttyp", "ttys", "pty"):
+		return KindPTY
+	}
+	return KindUnknown
+}
+
+// isVirtualConsoleName reports whether name is a Linux virtual console name such as tty1.
+func isVirtualConsoleName(name string) bool {
+	digits, ok := strings.CutPrefix(name, "tty")
+	if !ok || digits == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(digits, 10, 8)
+	return err == nil
+}
+
+// hasAnyPrefix reports whether s starts with any of the prefixes.
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
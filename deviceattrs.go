@@ -0,0 +1,190 @@
+package probe
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// Requests for the Secondary and Tertiary Device Attributes; the primary request is the DA1 sentinel.
+const (
+	da2Request = "\x1b[>c"
+	da3Request = "\x1b[=c"
+)
+
+// Feature is an extension a terminal advertises in its Primary Device Attributes reply.
+// Its values are the attribute codes used by DEC terminals and the emulators that follow them.
+type Feature int
+
+// These constants enumerate the features defined by DEC terminals from the VT220 on.
+const (
+	Feature132Columns          Feature = 1  // 132 column mode
+	FeaturePrinter             Feature = 2  // Printer port
+	FeatureReGIS               Feature = 3  // ReGIS vector graphics
+	FeatureSixel               Feature = 4  // Sixel raster graphics
+	FeatureSelectiveErase      Feature = 6  // Erasing only unprotected characters (DECSED and DECSEL)
+	FeatureSoftCharacterSets   Feature = 7  // Downloadable character sets (DRCS)
+	FeatureUserDefinedKeys     Feature = 8  // User-defined keys (DECUDK)
+	FeatureNationalCharacters  Feature = 9  // National replacement character sets
+	FeatureTechnicalCharacters Feature = 15 // DEC technical character set
+	FeatureLocator             Feature = 16 // Locator port, the DEC mouse protocol
+	FeatureTerminalState       Feature = 17 // Terminal state interrogation
+	FeatureWindowing           Feature = 18 // User windows
+	FeatureHorizontalScrolling Feature = 21 // Horizontal scrolling
+	FeatureANSIColor           Feature = 22 // ANSI color, the SGR color attributes
+	FeatureRectangularEditing  Feature = 28 // Rectangular area operations such as DECCRA and DECFRA
+	FeatureANSITextLocator     Feature = 29 // ANSI text locator
+)
+
+// featureNames maps features to the names returned by Feature.String.
+var featureNames = map[Feature]string{
+	Feature132Columns:          "132-columns",
+	FeaturePrinter:             "printer",
+	FeatureReGIS:               "regis",
+	FeatureSixel:               "sixel",
+	FeatureSelectiveErase:      "selective-erase",
+	FeatureSoftCharacterSets:   "soft-character-sets",
+	FeatureUserDefinedKeys:     "user-defined-keys",
+	FeatureNationalCharacters:  "national-characters",
+	FeatureTechnicalCharacters: "technical-characters",
+	FeatureLocator:             "locator",
+	FeatureTerminalState:       "terminal-state",
+	FeatureWindowing:           "windowing",
+	FeatureHorizontalScrolling: "horizontal-scrolling",
+	FeatureANSIColor:           "ansi-color",
+	FeatureRectangularEditing:  "rectangular-editing",
+	FeatureANSITextLocator:     "ansi-text-locator",
+}
+
+// String returns the name of the feature, such as "sixel", or "unknown" for codes not listed above.
+func (f Feature) String() string {
+	if name, ok := featureNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// PrimaryAttributes is the reply to a Primary Device Attributes request (DA1), "CSI ? class ; features c".
+type PrimaryAttributes struct {
+	Class    int       `json:"class"`    // Raw first parameter: 1 or 6 for the VT100 family, 6x for a VT200 or later
+	Level    int       `json:"level"`    // Conformance level: 1 for the VT100 family, 2 for the VT200, up to 5 for the VT500
+	Features []Feature `json:"features"` // Advertised features, in the order sent; empty for the VT100 family
+}
+
+// Has reports whether the terminal advertised the feature.
+func (a PrimaryAttributes) Has(f Feature) bool {
+	return slices.Contains(a.Features, f)
+}
+
+// SecondaryAttributes is the reply to a Secondary Device Attributes request (DA2), "CSI > type ; firmware ; rom c".
+// Emulators use the fields freely: most report the terminal type they emulate and their own version as firmware.
+type SecondaryAttributes struct {
+	TerminalType int `json:"terminal_type"` // Terminal type id, see TypeName
+	Firmware     int `json:"firmware"`      // Firmware version, which emulators use for their own version
+	ROM          int `json:"rom"`           // ROM cartridge registration number, usually zero
+}
+
+// terminalTypes maps DA2 terminal type ids to names, for the DEC terminals and the emulators that use their own ids.
+var terminalTypes = map[int]string{
+	0:   "VT100",
+	1:   "VT220",
+	2:   "VT240",
+	18:  "VT330",
+	19:  "VT340",
+	24:  "VT320",
+	28:  "DECterm",
+	41:  "VT420",
+	61:  "VT510",
+	64:  "VT520",
+	65:  "VT525",
+	'C': "Contour",
+	'M': "mintty",
+	'R': "rxvt",
+	'S': "GNU screen",
+	'T': "tmux",
+	'U': "rxvt-unicode",
+}
+
+// TypeName returns the name of the terminal type id, such as "VT420" for the id xterm reports
+// or "tmux" inside tmux, or an empty string if the id is not known.
+func (a SecondaryAttributes) TypeName() string {
+	return terminalTypes[a.TerminalType]
+}
+
+// TertiaryAttributes is the reply to a Tertiary Device Attributes request (DA3), "DCS ! | unit ST".
+type TertiaryAttributes struct {
+	UnitID string `json:"unit_id"` // Unit id as sent, eight hexadecimal digits; some emulators encode their name in ASCII
+}
+
+// PrimaryDeviceAttributes sends a Primary Device Attributes request (DA1), which every terminal answers,
+// and returns the conformance level and features it advertises.
+// If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned when nothing answers in time.
+// The file descriptor must be readable and writable; a *NotTerminalError is returned otherwise.
+func PrimaryDeviceAttributes(ctx context.Context, fd uintptr) (PrimaryAttributes, error) {
+	_, da1, err := query(ctx, fd, "")
+	if err != nil {
+		return PrimaryAttributes{}, err
+	}
+	return parsePrimaryAttributes(da1), nil
+}
+
+// SecondaryDeviceAttributes sends a Secondary Device Attributes request (DA2) and returns the terminal type
+// and firmware version. It reports false, with a nil error, if the terminal answered only the DA1 sentinel.
+// Inside a multiplexer the multiplexer answers, not the terminal emulator.
+// Timeouts and file descriptors are handled as by PrimaryDeviceAttributes.
+func SecondaryDeviceAttributes(ctx context.Context, fd uintptr) (attrs SecondaryAttributes, ok bool, err error) {
+	replies, _, err := query(ctx, fd, da2Request)
+	if err != nil {
+		return SecondaryAttributes{}, false, err
+	}
+
+	for _, reply := range replies {
+		if reply.intro != '[' || reply.final != 'c' || !reply.hasPrefix(">") {
+			continue
+		}
+		p := reply.params()
+		if len(p) == 0 || p[0] < 0 {
+			continue
+		}
+		p = append(p, 0, 0)
+		return SecondaryAttributes{TerminalType: p[0], Firmware: max(p[1], 0), ROM: max(p[2], 0)}, true, nil
+	}
+	return SecondaryAttributes{}, false, nil
+}
+
+// TertiaryDeviceAttributes sends a Tertiary Device Attributes request (DA3) and returns the unit id.
+// It reports false, with a nil error, if the terminal answered only the DA1 sentinel, as many emulators do.
+// Timeouts and file descriptors are handled as by PrimaryDeviceAttributes.
+func TertiaryDeviceAttributes(ctx context.Context, fd uintptr) (attrs TertiaryAttributes, ok bool, err error) {
+	replies, _, err := query(ctx, fd, da3Request)
+	if err != nil {
+		return TertiaryAttributes{}, false, err
+	}
+
+	for _, reply := range replies {
+		if reply.intro == 'P' && reply.hasPrefix("!|") {
+			return TertiaryAttributes{UnitID: strings.ToUpper(reply.body[2:])}, true, nil
+		}
+	}
+	return TertiaryAttributes{}, false, nil
+}
+
+// parsePrimaryAttributes decodes a DA1 reply. Terminals of the VT100 family send option bits
+// rather than feature codes after the class, so no features are reported for them.
+func parsePrimaryAttributes(da1 sequence) PrimaryAttributes {
+	p := da1.params()
+	if len(p) == 0 {
+		return PrimaryAttributes{}
+	}
+
+	a := PrimaryAttributes{Class: p[0], Level: 1}
+	if p[0] >= 60 {
+		a.Level = p[0] - 60
+		for _, code := range p[1:] {
+			if code >= 0 {
+				a.Features = append(a.Features, Feature(code))
+			}
+		}
+	}
+	return a
+}
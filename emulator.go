@@ -0,0 +1,162 @@
+package probe
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Names of the terminal emulators recognized by detectEmulator.
+const (
+	emulatorUnknown         = ""
+	emulatorAlacritty       = "alacritty"
+	emulatorAppleTerminal   = "apple-terminal"
+	emulatorDumb            = "dumb"
+	emulatorEmacs           = "emacs"
+	emulatorFoot            = "foot"
+	emulatorGhostty         = "ghostty"
+	emulatorHyper           = "hyper"
+	emulatorITerm2          = "iterm2"
+	emulatorJediTerm        = "jediterm"
+	emulatorKitty           = "kitty"
+	emulatorKonsole         = "konsole"
+	emulatorLinux           = "linux"
+	emulatorMintty          = "mintty"
+	emulatorVSCode          = "vscode"
+	emulatorVTE             = "vte"
+	emulatorWarp            = "warp"
+	emulatorWezTerm         = "wezterm"
+	emulatorWindowsTerminal = "windows-terminal"
+	emulatorXterm           = "xterm"
+)
+
+// Names of the terminal multiplexers recognized by detectEmulator.
+const (
+	multiplexerScreen = "screen"
+	multiplexerTmux   = "tmux"
+)
+
+// emulator describes the terminal emulator the process runs in, as far as the environment tells.
+type emulator struct {
+	name               string // One of the emulator constants, or emulatorUnknown
+	version            string // Version reported by the emulator, in its own format, or empty
+	multiplexer        string // One of the multiplexer constants when running inside one, or empty
+	multiplexerVersion string // Version of the multiplexer, when it reports one
+}
+
+// detectEmulator identifies the terminal emulator from the variables it exports into the environment.
+// Inside a multiplexer the outer emulator is still reported when its variables survive, as they usually do.
+func detectEmulator() emulator {
+	var e emulator
+
+	term := os.Getenv("TERM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "tmux"):
+		e.multiplexer = multiplexerTmux
+	case os.Getenv("STY") != "" || strings.HasPrefix(term, "screen"):
+		e.multiplexer = multiplexerScreen
+	}
+
+	switch program := os.Getenv("TERM_PROGRAM"); program {
+	case "iTerm.app":
+		e.name = emulatorITerm2
+	case "Apple_Terminal":
+		e.name = emulatorAppleTerminal
+	case "vscode":
+		e.name = emulatorVSCode
+	case "WezTerm":
+		e.name = emulatorWezTerm
+	case "ghostty":
+		e.name = emulatorGhostty
+	case "Hyper":
+		e.name = emulatorHyper
+	case "mintty":
+		e.name = emulatorMintty
+	case "WarpTerminal":
+		e.name = emulatorWarp
+	case "tmux":
+		// tmux replaces the outer emulator's TERM_PROGRAM with its own.
+		e.multiplexer, e.multiplexerVersion = multiplexerTmux, os.Getenv("TERM_PROGRAM_VERSION")
+	}
+	if e.name != emulatorUnknown {
+		e.version = os.Getenv("TERM_PROGRAM_VERSION")
+		return e
+	}
+
+	switch {
+	case os.Getenv("LC_TERMINAL") == "iTerm2":
+		// iTerm2 also sets LC_TERMINAL, which is passed through SSH and survives tmux.
+		e.name, e.version = emulatorITerm2, os.Getenv("LC_TERMINAL_VERSION")
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty":
+		e.name = emulatorKitty
+	case os.Getenv("WEZTERM_EXECUTABLE") != "" || term == "wezterm":
+		e.name = emulatorWezTerm
+	case os.Getenv("ALACRITTY_WINDOW_ID") != "" || os.Getenv("ALACRITTY_LOG") != "" || term == "alacritty":
+		e.name = emulatorAlacritty
+	case os.Getenv("WT_SESSION") != "":
+		e.name = emulatorWindowsTerminal
+	case os.Getenv("KONSOLE_VERSION") != "":
+		e.name, e.version = emulatorKonsole, os.Getenv("KONSOLE_VERSION")
+	case os.Getenv("VTE_VERSION") != "":
+		e.name, e.version = emulatorVTE, os.Getenv("VTE_VERSION")
+	case os.Getenv("TERMINAL_EMULATOR") == "JetBrains-JediTerm":
+		e.name = emulatorJediTerm
+	case os.Getenv("INSIDE_EMACS") != "":
+		e.name = emulatorEmacs
+	case term == "foot" || strings.HasPrefix(term, "foot-"):
+		e.name = emulatorFoot
+	case term == "linux":
+		e.name = emulatorLinux
+	case term == "dumb":
+		e.name = emulatorDumb
+	case os.Getenv("XTERM_VERSION") != "":
+		e.name, e.version = emulatorXterm, os.Getenv("XTERM_VERSION")
+	}
+	return e
+}
+
+// screenChunkSize is the longest string GNU screen passes through in one DCS sequence, with room to spare.
+const screenChunkSize = 76
+
+// passthrough wraps seq so that the multiplexer forwards it to the outer terminal instead of interpreting it.
+// tmux needs allow-passthrough enabled and expects escapes doubled; GNU screen limits the length of each
+// DCS string, so seq is split across several and must not contain ST itself (use BEL to end OSC sequences).
+// Outside a multiplexer seq is returned unchanged.
+func passthrough(seq, multiplexer string) string {
+	switch multiplexer {
+	case multiplexerTmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case multiplexerScreen:
+		var b strings.Builder
+		for len(seq) > 0 {
+			n := min(len(seq), screenChunkSize)
+			b.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return b.String()
+	}
+	return seq
+}
+
+// versionAtLeast reports whether a dotted version such as "3.4.1" or "3.4a" is at least the given components.
+// Non-numeric suffixes are ignored and missing components count as zero; an unparsable version is never enough.
+func versionAtLeast(version string, min ...int) bool {
+	parts := strings.Split(version, ".")
+	for i, want := range min {
+		got := 0
+		if i < len(parts) {
+			digits := 0
+			for digits < len(parts[i]) && parts[i][digits] >= '0' && parts[i][digits] <= '9' {
+				digits++
+			}
+			if digits == 0 {
+				return false
+			}
+			got, _ = strconv.Atoi(parts[i][:digits])
+		}
+		if got != want {
+			return got > want
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInterrupted is returned when the user presses Ctrl-C while ReadPassword is reading,
+// or when a termination signal arrives during an operation and the program handles the signal itself.
+var ErrInterrupted = errors.New("probe: interrupted")
+
+// NotTerminalError is returned when an operation requires a terminal but the file descriptor is not one.
+// Use errors.As to detect it and decide whether to fall back to non-interactive behavior.
+type NotTerminalError struct {
+	Fd uintptr // The file descriptor that was rejected
+}
+
+// Error implements the error interface.
+func (e *NotTerminalError) Error() string {
+	return fmt.Sprintf("probe: file descriptor %d is not a terminal", e.Fd)
+}
+
+// ErrNoReply is returned when a terminal query times out before the terminal answers.
+// It usually means the output is not connected to a real terminal emulator, or the connection is very slow.
+var ErrNoReply = errors.New("probe: terminal did not reply")
+
+// ErrUnknownMouseMode is returned when EnableMouse is given a tracking mode or encoding that is not one of the constants.
+var ErrUnknownMouseMode = errors.New("probe: unknown mouse tracking mode or encoding")
+
+// ErrSessionClosed is returned when a mode change is requested through a Session that has been closed.
+var ErrSessionClosed = errors.New("probe: session is closed")
+
+// ErrClipboardUnavailable is returned when the terminal cannot be used to access the clipboard,
+// either because it does not support OSC 52 or because the user or its configuration denied access.
+var ErrClipboardUnavailable = errors.New("probe: clipboard unsupported or access denied")
+
+// ErrClipboardTooLarge is returned when data is larger than the terminal accepts in a single clipboard write.
+var ErrClipboardTooLarge = errors.New("probe: data too large for the terminal clipboard")
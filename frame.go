@@ -0,0 +1,98 @@
+package probe
+
+import (
+	"context"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Sequences that begin and end a synchronized update (BSU and ESU), by setting and resetting mode 2026.
+const (
+	beginSynchronizedUpdate = "\x1b[?2026h"
+	endSynchronizedUpdate   = "\x1b[?2026l"
+)
+
+// SupportsSynchronizedOutput reports whether the terminal supports synchronized output (mode 2026),
+// which holds back screen updates until a frame is complete so that fast redraws do not tear.
+// It asks for the mode with DECRQM and reports true if the terminal recognizes it and lets it be changed.
+// If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned when nothing answers in time.
+// The file descriptor must be readable and writable; a *NotTerminalError is returned otherwise.
+func SupportsSynchronizedOutput(ctx context.Context, fd uintptr) (bool, error) {
+	status, err := QueryMode(ctx, fd, modeSynchronized)
+	if err != nil {
+		return false, err
+	}
+	return status.Changeable(), nil
+}
+
+// FrameWriter buffers the output of a screen redraw and writes it to the terminal as one frame.
+// When the terminal supports synchronized output each frame is wrapped in BSU and ESU,
+// so that the terminal shows it at once; otherwise frames are written as plain buffered output.
+// Either way a frame is written with a single write call.
+//
+// A FrameWriter is not safe for concurrent use.
+type FrameWriter struct {
+	fd           uintptr // File descriptor the frames are written to
+	synchronized bool    // Whether frames are wrapped in BSU and ESU
+	buf          []byte  // The current frame, starting with BSU when synchronized
+}
+
+// NewFrameWriter returns a FrameWriter for fd, checking with SupportsSynchronizedOutput whether
+// frames can be synchronized. The check is skipped when fd is not a terminal, and any failure,
+// such as a terminal that does not answer before ctx ends, falls back to plain buffered writes.
+func NewFrameWriter(ctx context.Context, fd uintptr) *FrameWriter {
+	w := &FrameWriter{fd: fd}
+	if IsTerminal(fd) {
+		w.synchronized, _ = SupportsSynchronizedOutput(ctx, fd)
+	}
+	w.reset()
+	return w
+}
+
+// Synchronized reports whether frames are wrapped in BSU and ESU.
+func (w *FrameWriter) Synchronized() bool {
+	return w.synchronized
+}
+
+// Write adds p to the current frame. It never fails.
+func (w *FrameWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// WriteString adds s to the current frame. It never fails.
+func (w *FrameWriter) WriteString(s string) (int, error) {
+	w.buf = append(w.buf, s...)
+	return len(s), nil
+}
+
+// Buffered returns the number of bytes in the current frame.
+func (w *FrameWriter) Buffered() int {
+	if w.synchronized {
+		return len(w.buf) - len(beginSynchronizedUpdate)
+	}
+	return len(w.buf)
+}
+
+// Flush writes the current frame with a single write and starts a new one.
+// Nothing is written when the frame is empty. The frame is discarded even if the write fails,
+// as the next redraw replaces it anyway.
+func (w *FrameWriter) Flush() error {
+	if w.Buffered() == 0 {
+		return nil
+	}
+	if w.synchronized {
+		w.buf = append(w.buf, endSynchronizedUpdate...)
+	}
+	_, err := platform.Write(w.fd, w.buf)
+	w.reset()
+	return err
+}
+
+// reset starts a new frame, reusing the buffer.
+func (w *FrameWriter) reset() {
+	w.buf = w.buf[:0]
+	if w.synchronized {
+		w.buf = append(w.buf, beginSynchronizedUpdate...)
+	}
+}
@@ -0,0 +1,71 @@
+package probe
+
+import (
+	"context"
+)
+
+// Requests sent by GraphicsSupport, answered in a single round trip before the DA1 sentinel.
+const (
+	kittyGraphicsQuery = "\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\" // Query a 1x1 RGB image without storing it
+	sixelGeometryQuery = "\x1b[?2;1;0S"                               // XTSMGRAPHICS: read the largest sixel image size
+	cellSizeQuery      = "\x1b[16t"                                   // XTWINOPS: report the cell size in pixels
+)
+
+// Graphics describes the inline image protocols a terminal supports.
+type Graphics struct {
+	Sixel       bool `json:"sixel"`        // Sixel images, advertised in the DA1 reply
+	SixelWidth  int  `json:"sixel_width"`  // Largest sixel image width in pixels from XTSMGRAPHICS, or zero if unknown
+	SixelHeight int  `json:"sixel_height"` // Largest sixel image height in pixels from XTSMGRAPHICS, or zero if unknown
+	Kitty       bool `json:"kitty"`        // The kitty graphics protocol, which answered a query for a test image
+	ITerm2      bool `json:"iterm2"`       // iTerm2 inline images, inferred from the emulator
+	CellWidth   int  `json:"cell_width"`   // Width of a character cell in pixels, or zero if unknown
+	CellHeight  int  `json:"cell_height"`  // Height of a character cell in pixels, or zero if unknown
+}
+
+// GraphicsSupport detects which inline image protocols the terminal supports and the pixel size of its cells,
+// which is needed to scale images to a number of rows and columns.
+// It sends a kitty graphics query, an XTSMGRAPHICS geometry request and a cell size request followed by
+// the DA1 sentinel, and reads the replies in one round trip. Inside tmux or GNU screen the requests are
+// passed through to the outer terminal. iTerm2 inline images have no query, so they are inferred from the emulator.
+// The cell size comes from TIOCGWINSZ when the terminal fills in the pixel fields, and from CSI 16 t otherwise.
+// If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned when nothing answers in time.
+// The file descriptor must be readable and writable; a *NotTerminalError is returned otherwise.
+func GraphicsSupport(ctx context.Context, fd uintptr) (Graphics, error) {
+	e := detectEmulator()
+	request := kittyGraphicsQuery + sixelGeometryQuery + cellSizeQuery + da1Request
+	replies, da1, err := exchange(ctx, fd, passthrough(request, e.multiplexer))
+	if err != nil {
+		return Graphics{}, err
+	}
+
+	g := Graphics{
+		Sixel: parsePrimaryAttributes(da1).Has(FeatureSixel),
+	}
+	switch e.name {
+	case emulatorITerm2, emulatorWezTerm, emulatorMintty:
+		g.ITerm2 = true
+	}
+
+	for _, reply := range replies {
+		switch {
+		case reply.intro == '_' && reply.hasPrefix("Gi=31;"):
+			g.Kitty = reply.body[len("Gi=31;"):] == "OK"
+		case reply.intro == '[' && reply.final == 'S' && reply.hasPrefix("?2;"):
+			// "CSI ? 2 ; status ; width ; height S", where a zero status means success.
+			if p := reply.params(); len(p) == 4 && p[1] == 0 && p[2] > 0 && p[3] > 0 {
+				g.SixelWidth, g.SixelHeight = p[2], p[3]
+			}
+		case reply.intro == '[' && reply.final == 't' && reply.hasPrefix("6;"):
+			// "CSI 6 ; height ; width t".
+			if p := reply.params(); len(p) == 3 && p[1] > 0 && p[2] > 0 {
+				g.CellWidth, g.CellHeight = p[2], p[1]
+			}
+		}
+	}
+
+	// The kernel's pixel size is preferred when set, as it is exact even when CSI 16 t is not answered.
+	if size, err := Size(fd); err == nil && size.Columns > 0 && size.Rows > 0 && size.Width > 0 && size.Height > 0 {
+		g.CellWidth, g.CellHeight = size.Width/size.Columns, size.Height/size.Rows
+	}
+	return g, nil
+}
@@ -0,0 +1,115 @@
+package probe
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HyperlinkOption configures Hyperlink.
+type HyperlinkOption func(*hyperlinkOptions)
+
+// hyperlinkOptions holds the settings applied by HyperlinkOption functions.
+type hyperlinkOptions struct {
+	id          string // Identifier grouping the parts of a link split across lines
+	urlFallback bool   // Whether to append the URL to the text when links are not supported
+}
+
+// WithLinkID sets the id parameter of the link, so that terminals highlight every part of a link
+// that is split across lines or cells, such as a path wrapped by a table, as one link.
+// Characters that would end the parameter early are removed.
+func WithLinkID(id string) HyperlinkOption {
+	return func(o *hyperlinkOptions) {
+		o.id = id
+	}
+}
+
+// WithURLFallback writes "text (url)" instead of just the text when hyperlinks are not supported,
+// so that the target stays visible. Nothing is appended when the text already is the URL.
+func WithURLFallback() HyperlinkOption {
+	return func(o *hyperlinkOptions) {
+		o.urlFallback = true
+	}
+}
+
+// SupportsHyperlinks returns true if text written to the file descriptor can contain OSC 8 hyperlinks.
+// The FORCE_HYPERLINK environment variable overrides the detection: any value other than "0" enables links,
+// even when the file descriptor is not a terminal, and "0" disables them.
+// Otherwise the file descriptor must be a terminal whose emulator and version are known to support them;
+// inside tmux this also requires tmux 3.4 or later, and inside GNU screen links are never used.
+// The result is not cached because it depends on the environment.
+func SupportsHyperlinks(fd uintptr) bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		return force != "0"
+	}
+	if !IsTerminal(fd) {
+		return false
+	}
+
+	e := detectEmulator()
+	switch e.multiplexer {
+	case multiplexerScreen:
+		return false
+	case multiplexerTmux:
+		if !versionAtLeast(e.multiplexerVersion, 3, 4) {
+			return false
+		}
+	}
+
+	switch e.name {
+	case emulatorKitty, emulatorWezTerm, emulatorFoot, emulatorGhostty, emulatorAlacritty, emulatorWindowsTerminal, emulatorJediTerm, emulatorWarp:
+		return true
+	case emulatorITerm2:
+		return versionAtLeast(e.version, 3, 1)
+	case emulatorVSCode:
+		return versionAtLeast(e.version, 1, 72)
+	case emulatorMintty:
+		return versionAtLeast(e.version, 2, 9, 7)
+	case emulatorHyper:
+		return versionAtLeast(e.version, 3)
+	case emulatorVTE:
+		// VTE_VERSION encodes 0.50.1 as 5001; links work from 0.50.1, as 0.50.0 could crash on them.
+		version, err := strconv.Atoi(e.version)
+		return err == nil && version >= 5001
+	}
+	return false
+}
+
+// Hyperlink returns text formatted as an OSC 8 hyperlink to url for the terminal on fd,
+// or the plain text when SupportsHyperlinks reports false, for example when output is piped.
+// Control characters are removed from every part so that they cannot end the sequence early.
+func Hyperlink(fd uintptr, url, text string, opts ...HyperlinkOption) string {
+	o := hyperlinkOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if !SupportsHyperlinks(fd) {
+		if o.urlFallback && url != "" && text != url {
+			return text + " (" + url + ")"
+		}
+		return text
+	}
+
+	var params string
+	if id := strings.Map(dropLinkParam, o.id); id != "" {
+		params = "id=" + id
+	}
+	return "\x1b]8;" + params + ";" + strings.Map(dropControl, url) + "\x1b\\" + strings.Map(dropControl, text) + "\x1b]8;;\x1b\\"
+}
+
+// dropControl removes control characters, for use with strings.Map.
+func dropControl(r rune) rune {
+	if r < 0x20 || r >= 0x7f && r < 0xa0 {
+		return -1
+	}
+	return r
+}
+
+// dropLinkParam removes control characters and the separators of OSC 8 parameters, for use with strings.Map.
+func dropLinkParam(r rune) rune {
+	if r == ':' || r == ';' || r == '=' {
+		return -1
+	}
+	return dropControl(r)
+}
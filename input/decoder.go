@@ -0,0 +1,460 @@
+package input
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Key codes of the kitty keyboard protocol outside the ranges covered by kittyKeys.
+const (
+	kittyFunctionFirst = 57344 // First code of the private use range the protocol assigns to functional keys
+	kittyFunctionLast  = 63743 // Last code of that range
+	kittyMeta          = 32    // Modifier bit of kitty's Meta, distinct from the Super key xterm calls Meta
+)
+
+// esc is the escape character that starts every escape sequence.
+const esc = 0x1b
+
+// pasteEnd is the marker that ends bracketed paste.
+var pasteEnd = []byte("\x1b[201~")
+
+// pasteStart is returned by the parser for the marker that starts bracketed paste.
+// It switches the decoder into paste mode and is never delivered to callers.
+type pasteStart struct{}
+
+// event marks pasteStart as an Event.
+func (pasteStart) event() {}
+
+// tildeKeys maps the first parameter of a VT220-style "CSI n ~" sequence to a key.
+var tildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPageUp, 6: KeyPageDown, 7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12, 25: KeyF13, 26: KeyF14, 28: KeyF15, 29: KeyF16,
+	31: KeyF17, 32: KeyF18, 33: KeyF19, 34: KeyF20,
+}
+
+// letterKeys maps the final byte of xterm-style "CSI 1;m X" and "SS3 X" sequences to a key.
+var letterKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'E': KeyBegin, 'F': KeyEnd, 'H': KeyHome,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// keypadRunes maps the final byte of application keypad "SS3 X" sequences to the character of the key.
+var keypadRunes = map[byte]rune{
+	'j': '*', 'k': '+', 'l': ',', 'm': '-', 'n': '.', 'o': '/', 'X': '=',
+	'p': '0', 'q': '1', 'r': '2', 's': '3', 't': '4', 'u': '5', 'v': '6', 'w': '7', 'x': '8', 'y': '9',
+}
+
+// kittyKeys maps the key codes of the kitty keyboard protocol that are not plain characters to a key.
+// Keypad keys are reported like their main keyboard counterparts.
+var kittyKeys = map[int]KeyEvent{
+	9: {Key: KeyTab}, 13: {Key: KeyEnter}, 27: {Key: KeyEscape}, 127: {Key: KeyBackspace},
+	57376: {Key: KeyF13}, 57377: {Key: KeyF14}, 57378: {Key: KeyF15}, 57379: {Key: KeyF16},
+	57380: {Key: KeyF17}, 57381: {Key: KeyF18}, 57382: {Key: KeyF19}, 57383: {Key: KeyF20},
+	57399: {Key: KeyRune, Rune: '0'}, 57400: {Key: KeyRune, Rune: '1'}, 57401: {Key: KeyRune, Rune: '2'},
+	57402: {Key: KeyRune, Rune: '3'}, 57403: {Key: KeyRune, Rune: '4'}, 57404: {Key: KeyRune, Rune: '5'},
+	57405: {Key: KeyRune, Rune: '6'}, 57406: {Key: KeyRune, Rune: '7'}, 57407: {Key: KeyRune, Rune: '8'},
+	57408: {Key: KeyRune, Rune: '9'}, 57409: {Key: KeyRune, Rune: '.'}, 57410: {Key: KeyRune, Rune: '/'},
+	57411: {Key: KeyRune, Rune: '*'}, 57412: {Key: KeyRune, Rune: '-'}, 57413: {Key: KeyRune, Rune: '+'},
+	57414: {Key: KeyEnter}, 57415: {Key: KeyRune, Rune: '='}, 57416: {Key: KeyRune, Rune: ','},
+	57417: {Key: KeyLeft}, 57418: {Key: KeyRight}, 57419: {Key: KeyUp}, 57420: {Key: KeyDown},
+	57421: {Key: KeyPageUp}, 57422: {Key: KeyPageDown}, 57423: {Key: KeyHome}, 57424: {Key: KeyEnd},
+	57425: {Key: KeyInsert}, 57426: {Key: KeyDelete}, 57427: {Key: KeyBegin},
+}
+
+// Decoder turns a stream of bytes read from a terminal into events.
+// Bytes may be fed in arbitrary chunks: incomplete sequences are kept until more data arrives or Flush is called.
+// A Decoder is not safe for concurrent use.
+type Decoder struct {
+	buf     []byte // Bytes that do not yet form a complete event
+	paste   []byte // Text collected since the start of a bracketed paste
+	pasting bool   // Whether the decoder is inside a bracketed paste
+}
+
+// NewDecoder returns a new Decoder with no pending input.
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// Decode appends p to the pending input and returns every event that is complete.
+func (d *Decoder) Decode(p []byte) []Event {
+	d.buf = append(d.buf, p...)
+	return d.drain(false)
+}
+
+// Pending reports whether the decoder holds an incomplete sequence, such as a lone escape.
+// Input held inside an unfinished bracketed paste is not pending, because it must not be flushed as keys.
+func (d *Decoder) Pending() bool {
+	return len(d.buf) > 0 && !d.pasting
+}
+
+// Flush decodes the pending input as if no more bytes will follow.
+// A lone escape becomes KeyEscape, and a truncated escape sequence becomes an Alt-modified key.
+// Callers invoke it once the escape timeout has elapsed without further input.
+func (d *Decoder) Flush() []Event {
+	return d.drain(true)
+}
+
+// drain decodes as many events as possible from the pending input.
+func (d *Decoder) drain(flush bool) []Event {
+	var events []Event
+	i := 0
+	for i < len(d.buf) {
+		if d.pasting {
+			ev, n := d.parsePaste(d.buf[i:])
+			if n == 0 {
+				break
+			}
+			if ev != nil {
+				events = append(events, ev)
+			}
+			i += n
+			continue
+		}
+
+		ev, n := parse(d.buf[i:], flush)
+		if n == 0 {
+			break
+		}
+		switch ev.(type) {
+		case nil:
+		case pasteStart:
+			d.pasting = true
+		default:
+			events = append(events, ev)
+		}
+		i += n
+	}
+	d.buf = append(d.buf[:0], d.buf[i:]...)
+	return events
+}
+
+// parsePaste collects pasted text until the end marker, which may arrive in a later read.
+// It keeps back a trailing partial marker so that it can be matched once the rest arrives.
+func (d *Decoder) parsePaste(p []byte) (Event, int) {
+	if end := bytes.Index(p, pasteEnd); end >= 0 {
+		d.paste = append(d.paste, p[:end]...)
+		ev := PasteEvent{Text: string(d.paste)}
+		d.paste = d.paste[:0]
+		d.pasting = false
+		return ev, end + len(pasteEnd)
+	}
+
+	keep := 0
+	for n := min(len(p), len(pasteEnd)-1); n > 0; n-- {
+		if bytes.HasPrefix(pasteEnd, p[len(p)-n:]) {
+			keep = n
+			break
+		}
+	}
+	d.paste = append(d.paste, p[:len(p)-keep]...)
+	return nil, len(p) - keep
+}
+
+// parse decodes a single event from the start of p and returns it with the number of bytes consumed.
+// It returns zero bytes consumed when p holds an incomplete sequence and flush is false.
+func parse(p []byte, flush bool) (Event, int) {
+	c := p[0]
+	switch {
+	case c == esc:
+		return parseEscape(p, flush)
+	case c < utf8.RuneSelf:
+		return parseByte(c), 1
+	case !utf8.FullRune(p):
+		if !flush {
+			return nil, 0
+		}
+		return KeyEvent{Key: KeyRune, Rune: utf8.RuneError}, 1
+	}
+
+	r, size := utf8.DecodeRune(p)
+	return KeyEvent{Key: KeyRune, Rune: r}, size
+}
+
+// parseByte decodes a single ASCII byte, mapping control characters to Ctrl-modified keys.
+func parseByte(c byte) KeyEvent {
+	switch c {
+	case '\r':
+		return KeyEvent{Key: KeyEnter}
+	case '\t':
+		return KeyEvent{Key: KeyTab}
+	case 0x7f:
+		return KeyEvent{Key: KeyBackspace}
+	case esc:
+		return KeyEvent{Key: KeyEscape}
+	case 0x00:
+		return KeyEvent{Key: KeyRune, Rune: ' ', Mod: ModCtrl}
+	}
+
+	switch {
+	case c < esc:
+		return KeyEvent{Key: KeyRune, Rune: rune('a' + c - 1), Mod: ModCtrl}
+	case c < ' ':
+		return KeyEvent{Key: KeyRune, Rune: rune(c + '@'), Mod: ModCtrl}
+	}
+	return KeyEvent{Key: KeyRune, Rune: rune(c)}
+}
+
+// parseEscape decodes an escape sequence or an Alt-prefixed key starting at p[0] == esc.
+func parseEscape(p []byte, flush bool) (Event, int) {
+	if len(p) == 1 {
+		if !flush {
+			return nil, 0
+		}
+		return KeyEvent{Key: KeyEscape}, 1
+	}
+
+	var ev Event
+	var n int
+	switch p[1] {
+	case '[':
+		ev, n = parseCSI(p)
+	case 'O':
+		ev, n = parseSS3(p)
+	case esc:
+		// A doubled escape is Alt combined with whatever the second escape starts.
+		ev, n = parseEscape(p[1:], flush)
+		if n == 0 {
+			return nil, 0
+		}
+		if key, ok := ev.(KeyEvent); ok {
+			key.Mod |= ModAlt
+			return key, n + 1
+		}
+		return KeyEvent{Key: KeyEscape}, 1
+	default:
+		ev, n = parse(p[1:], flush)
+		if n == 0 {
+			return nil, 0
+		}
+		key := ev.(KeyEvent)
+		key.Mod |= ModAlt
+		return key, n + 1
+	}
+
+	if n > 0 {
+		return ev, n
+	}
+	if !flush {
+		return nil, 0
+	}
+
+	// The sequence was cut short, so the escape was an Alt prefix for '[' or 'O'.
+	return KeyEvent{Key: KeyRune, Rune: rune(p[1]), Mod: ModAlt}, 2
+}
+
+// parseCSI decodes a control sequence introduced by "ESC [".
+func parseCSI(p []byte) (Event, int) {
+	// Legacy mouse reports are "ESC [ M" followed by three raw bytes offset by 32.
+	if len(p) > 2 && p[2] == 'M' {
+		if len(p) < 6 {
+			return nil, 0
+		}
+		return mouseEvent(int(p[3])-32, int(p[4])-33, int(p[5])-33, false), 6
+	}
+
+	// The Linux console encodes F1 to F5 as "ESC [ [ A" to "ESC [ [ E".
+	if len(p) > 2 && p[2] == '[' {
+		if len(p) < 4 {
+			return nil, 0
+		}
+		if p[3] >= 'A' && p[3] <= 'E' {
+			return KeyEvent{Key: KeyF1 + Key(p[3]-'A')}, 4
+		}
+		return UnknownEvent{Sequence: clone(p[:4])}, 4
+	}
+
+	for i := 2; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c >= 0x40 && c <= 0x7e:
+			return csiEvent(p[:i+1]), i + 1
+		case c < 0x20 || c > 0x7e:
+			// A control character aborts the sequence.
+			return UnknownEvent{Sequence: clone(p[:i])}, i
+		}
+	}
+	return nil, 0
+}
+
+// csiEvent converts a complete control sequence into an event.
+func csiEvent(seq []byte) Event {
+	final := seq[len(seq)-1]
+	body := seq[2 : len(seq)-1]
+
+	// SGR mouse reports are "CSI < button ; x ; y M" for presses and motion, and "m" for releases.
+	if len(body) > 0 && body[0] == '<' && (final == 'M' || final == 'm') {
+		params, ok := parseParams(body[1:])
+		if !ok || len(params) != 3 || param(params, 0, -1) < 0 || param(params, 1, 0) < 1 || param(params, 2, 0) < 1 {
+			return UnknownEvent{Sequence: clone(seq)}
+		}
+		return mouseEvent(param(params, 0, 0), param(params, 1, 0)-1, param(params, 2, 0)-1, final == 'm')
+	}
+
+	// Focus reports are "CSI I" when the terminal gains focus and "CSI O" when it loses it.
+	if len(body) == 0 && (final == 'I' || final == 'O') {
+		return FocusEvent{Focused: final == 'I'}
+	}
+
+	params, ok := parseParams(body)
+	if !ok {
+		return UnknownEvent{Sequence: clone(seq)}
+	}
+
+	// F3 is "CSI R" or "CSI 1;m R"; other sequences ending in R are cursor position reports.
+	if final == 'R' && len(params) > 0 && (param(params, 0, 1) != 1 || len(params) > 2) {
+		row, column := param(params, 0, 0), param(params, 1, 0)
+		if len(params) != 2 || row < 1 || column < 1 {
+			return UnknownEvent{Sequence: clone(seq)}
+		}
+		return CursorPositionEvent{X: column - 1, Y: row - 1}
+	}
+
+	// The kitty keyboard protocol appends the action to the modifier parameter, as in "CSI 1;5:3 A".
+	mod := modifier(param(params, 1, 1))
+	action := keyAction(subparam(params, 1, 1, 1))
+	if key, ok := letterKeys[final]; ok {
+		return KeyEvent{Key: key, Mod: mod, Action: action}
+	}
+
+	switch final {
+	case 'Z':
+		return KeyEvent{Key: KeyTab, Mod: ModShift | mod, Action: action}
+	case '~':
+		if param(params, 0, 0) == 200 {
+			return pasteStart{}
+		}
+		if key, ok := tildeKeys[param(params, 0, 0)]; ok {
+			return KeyEvent{Key: key, Mod: mod, Action: action}
+		}
+	case 'u':
+		if ev, ok := kittyEvent(params); ok {
+			ev.Mod, ev.Action = mod, action
+			return ev
+		}
+	}
+	return UnknownEvent{Sequence: clone(seq)}
+}
+
+// kittyEvent decodes the key code and associated text of a kitty keyboard protocol sequence,
+// "CSI code:shifted:base ; modifiers:action ; text u", where the text is a list of code points.
+// It reports false for codes that do not map to a Key, such as the lock and modifier keys themselves.
+func kittyEvent(params [][]int) (KeyEvent, bool) {
+	code := param(params, 0, -1)
+	ev, ok := kittyKeys[code]
+	if !ok {
+		if code < 0 || code >= kittyFunctionFirst && code <= kittyFunctionLast || !utf8.ValidRune(rune(code)) {
+			return KeyEvent{}, false
+		}
+		ev = KeyEvent{Key: KeyRune, Rune: rune(code)}
+	}
+
+	if len(params) > 2 {
+		var text []rune
+		for _, c := range params[2] {
+			if c > 0 && utf8.ValidRune(rune(c)) {
+				text = append(text, rune(c))
+			}
+		}
+		ev.Text = string(text)
+	}
+	return ev, true
+}
+
+// parseSS3 decodes a sequence introduced by "ESC O", used by terminals in application cursor mode.
+// Some terminals insert a modifier parameter, as in "ESC O 5 P" for Ctrl+F1.
+func parseSS3(p []byte) (Event, int) {
+	i := 2
+	mod := 0
+	for ; i < len(p) && p[i] >= '0' && p[i] <= '9'; i++ {
+		mod = mod*10 + int(p[i]-'0')
+	}
+	if i >= len(p) {
+		return nil, 0
+	}
+
+	final := p[i]
+	switch {
+	case final == 'M':
+		return KeyEvent{Key: KeyEnter, Mod: modifier(mod)}, i + 1
+	case letterKeys[final] != KeyUnknown:
+		return KeyEvent{Key: letterKeys[final], Mod: modifier(mod)}, i + 1
+	case keypadRunes[final] != 0:
+		return KeyEvent{Key: KeyRune, Rune: keypadRunes[final], Mod: modifier(mod)}, i + 1
+	}
+	return UnknownEvent{Sequence: clone(p[:i+1])}, i + 1
+}
+
+// parseParams splits the numeric parameters of a control sequence, such as "1;5:3".
+// Each parameter is returned as its value followed by any colon-separated sub-parameters.
+// It reports false if the parameters contain private markers or intermediate bytes.
+// Missing values are returned as -1.
+func parseParams(body []byte) ([][]int, bool) {
+	if len(body) == 0 {
+		return nil, true
+	}
+
+	params := [][]int{{-1}}
+	for _, c := range body {
+		cur := &params[len(params)-1]
+		switch {
+		case c >= '0' && c <= '9':
+			last := &(*cur)[len(*cur)-1]
+			if *last < 0 {
+				*last = 0
+			}
+			*last = *last*10 + int(c-'0')
+		case c == ':':
+			*cur = append(*cur, -1)
+		case c == ';':
+			params = append(params, []int{-1})
+		default:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// param returns the value of the parameter at index i, or def if it is missing.
+func param(params [][]int, i, def int) int {
+	return subparam(params, i, 0, def)
+}
+
+// subparam returns sub-parameter j of the parameter at index i, or def if it is missing.
+// Index zero is the parameter's own value.
+func subparam(params [][]int, i, j, def int) int {
+	if i >= len(params) || j >= len(params[i]) || params[i][j] < 0 {
+		return def
+	}
+	return params[i][j]
+}
+
+// keyAction converts the kitty keyboard protocol's event type, 1 for press, 2 for repeat and 3 for release.
+func keyAction(n int) KeyAction {
+	switch n {
+	case 2:
+		return KeyRepeat
+	case 3:
+		return KeyRelease
+	}
+	return KeyPress
+}
+
+// modifier converts an xterm modifier parameter, which is one plus the modifier bits, into a Modifier.
+// The kitty keyboard protocol's Meta bit is folded into ModMeta.
+func modifier(n int) Modifier {
+	if n < 2 {
+		return 0
+	}
+	bits := Modifier(n - 1)
+	if bits&kittyMeta != 0 {
+		bits = bits&^kittyMeta | ModMeta
+	}
+	return bits
+}
+
+// clone returns a copy of b so that events do not alias the decoder's buffer.
+func clone(b []byte) []byte {
+	return append([]byte(nil), b...)
+}
@@ -0,0 +1,48 @@
+package input
+
+import (
+	"context"
+)
+
+// FocusEvent reports that the terminal window gained or lost focus.
+// Terminals only send it while focus reporting is on; see WithFocus.
+type FocusEvent struct {
+	Focused bool // Whether the terminal now has focus
+}
+
+// event marks FocusEvent as an Event.
+func (FocusEvent) event() {}
+
+// String returns "focus-in" or "focus-out".
+func (e FocusEvent) String() string {
+	if e.Focused {
+		return "focus-in"
+	}
+	return "focus-out"
+}
+
+// WatchFocus reports whether the terminal window has focus, sending a value each time it gains or loses it.
+// It turns on focus reporting and reads events from fd until ctx is done, when reporting is turned off
+// and the channel is closed. Other input read meanwhile is discarded, so programs that also need keys
+// should use a Reader with WithFocus instead. Nothing is sent for terminals without focus reporting.
+func WatchFocus(ctx context.Context, fd uintptr, opts ...Option) <-chan bool {
+	ch := make(chan bool)
+	events := NewReader(fd, append(opts, WithFocus())...).Events(ctx)
+
+	go func() {
+		defer close(ch)
+		for ev := range events {
+			focus, ok := ev.(FocusEvent)
+			if !ok {
+				continue
+			}
+			select {
+			case ch <- focus.Focused:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
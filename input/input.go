@@ -0,0 +1,238 @@
+// Package input reads from a terminal and decodes the byte stream into events.
+//
+// It understands UTF-8 text, control keys, the legacy xterm and VT220 encodings of
+// function, arrow and editing keys including their modifiers, Alt-prefixed keys, bracketed paste,
+// mouse reports in both the SGR and legacy X10 encodings, focus reports, and the kitty keyboard protocol
+// with its release and repeat events and associated text.
+// A lone Escape key is told apart from the start of an escape sequence with a configurable timeout.
+package input
+
+import (
+	"strings"
+)
+
+// Event is a decoded input event, such as a KeyEvent.
+type Event interface {
+	event()
+}
+
+// Key identifies a key on the keyboard.
+// Printable characters and Ctrl combinations of letters are reported as KeyRune.
+type Key int
+
+// These constants enumerate the keys reported in a KeyEvent.
+const (
+	KeyUnknown Key = iota
+	KeyRune
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyBegin
+	KeyHome
+	KeyEnd
+	KeyInsert
+	KeyDelete
+	KeyPageUp
+	KeyPageDown
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+)
+
+// keyNames holds the names returned by Key.String, indexed by key.
+var keyNames = [...]string{
+	KeyUnknown:   "unknown",
+	KeyRune:      "rune",
+	KeyEnter:     "enter",
+	KeyTab:       "tab",
+	KeyBackspace: "backspace",
+	KeyEscape:    "esc",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyRight:     "right",
+	KeyLeft:      "left",
+	KeyBegin:     "begin",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyInsert:    "insert",
+	KeyDelete:    "delete",
+	KeyPageUp:    "pgup",
+	KeyPageDown:  "pgdown",
+	KeyF1:        "f1",
+	KeyF2:        "f2",
+	KeyF3:        "f3",
+	KeyF4:        "f4",
+	KeyF5:        "f5",
+	KeyF6:        "f6",
+	KeyF7:        "f7",
+	KeyF8:        "f8",
+	KeyF9:        "f9",
+	KeyF10:       "f10",
+	KeyF11:       "f11",
+	KeyF12:       "f12",
+	KeyF13:       "f13",
+	KeyF14:       "f14",
+	KeyF15:       "f15",
+	KeyF16:       "f16",
+	KeyF17:       "f17",
+	KeyF18:       "f18",
+	KeyF19:       "f19",
+	KeyF20:       "f20",
+}
+
+// String returns the lowercase name of the key, such as "enter" or "f5".
+func (k Key) String() string {
+	if k < 0 || int(k) >= len(keyNames) {
+		return keyNames[KeyUnknown]
+	}
+	return keyNames[k]
+}
+
+// Modifier is a set of modifier keys held down with a key.
+// The bit values match the xterm modifier parameter minus one.
+// The kitty keyboard protocol calls ModMeta Super, and its own Meta is reported as ModMeta too.
+type Modifier uint8
+
+// These constants enumerate the modifier keys and, for the kitty keyboard protocol, the lock keys.
+const (
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
+	ModMeta
+	ModHyper
+	_ // Kitty's Meta, folded into ModMeta
+	ModCapsLock
+	ModNumLock
+)
+
+// String returns the modifiers joined with "+", such as "ctrl+alt".
+func (m Modifier) String() string {
+	var names []string
+	if m&ModCtrl != 0 {
+		names = append(names, "ctrl")
+	}
+	if m&ModAlt != 0 {
+		names = append(names, "alt")
+	}
+	if m&ModShift != 0 {
+		names = append(names, "shift")
+	}
+	if m&ModMeta != 0 {
+		names = append(names, "meta")
+	}
+	if m&ModHyper != 0 {
+		names = append(names, "hyper")
+	}
+	if m&ModCapsLock != 0 {
+		names = append(names, "capslock")
+	}
+	if m&ModNumLock != 0 {
+		names = append(names, "numlock")
+	}
+	return strings.Join(names, "+")
+}
+
+// KeyAction is what happened to a key.
+// Terminals only report repeats and releases with the kitty keyboard protocol's KittyReportEvents flag.
+type KeyAction int
+
+// These constants enumerate the key actions.
+const (
+	KeyPress KeyAction = iota
+	KeyRepeat
+	KeyRelease
+)
+
+// String returns "press", "repeat" or "release".
+func (a KeyAction) String() string {
+	switch a {
+	case KeyRepeat:
+		return "repeat"
+	case KeyRelease:
+		return "release"
+	}
+	return "press"
+}
+
+// KeyEvent is a key press, or with the kitty keyboard protocol also a repeat or release.
+type KeyEvent struct {
+	Key    Key       // The key that was pressed
+	Rune   rune      // The character when Key is KeyRune
+	Mod    Modifier  // The modifiers held down with the key
+	Action KeyAction // Whether the key was pressed, repeated or released
+	Text   string    // The text the key produces, when the terminal reports it with KittyReportText
+}
+
+// event marks KeyEvent as an Event.
+func (KeyEvent) event() {}
+
+// String returns a readable description of the key press, such as "ctrl+c", "alt+x" or "shift+up".
+// Repeats and releases are suffixed with the action, as in "ctrl+c release".
+func (e KeyEvent) String() string {
+	name := e.Key.String()
+	if e.Key == KeyRune {
+		switch e.Rune {
+		case ' ':
+			name = "space"
+		default:
+			name = string(e.Rune)
+		}
+	}
+	if e.Mod != 0 {
+		name = e.Mod.String() + "+" + name
+	}
+	if e.Action != KeyPress {
+		name += " " + e.Action.String()
+	}
+	return name
+}
+
+// PasteEvent is text pasted into a terminal with bracketed paste mode enabled.
+// The text is delivered exactly as the terminal sent it; most terminals send line breaks as carriage returns.
+type PasteEvent struct {
+	Text string // The pasted text, without the bracketing markers
+}
+
+// event marks PasteEvent as an Event.
+func (PasteEvent) event() {}
+
+// CursorPositionEvent is a cursor position report, "CSI row ; column R", which a terminal sends in reply
+// to a query for the cursor position that reaches the input stream. Coordinates are zero-based cells.
+// A report of the first row with a column from 2 to 16 reads like F3 with modifiers and is decoded as a KeyEvent.
+type CursorPositionEvent struct {
+	X int // Column of the cursor
+	Y int // Row of the cursor
+}
+
+// event marks CursorPositionEvent as an Event.
+func (CursorPositionEvent) event() {}
+
+// UnknownEvent carries an escape sequence that was well-formed but not recognized.
+type UnknownEvent struct {
+	Sequence []byte // The raw bytes of the sequence, including the leading escape
+}
+
+// event marks UnknownEvent as an Event.
+func (UnknownEvent) event() {}
@@ -0,0 +1,133 @@
+package input
+
+// MouseButton identifies the mouse button or wheel direction of a MouseEvent.
+type MouseButton int
+
+// These constants enumerate the mouse buttons.
+const (
+	MouseNone MouseButton = iota // No button, as in motion without a button held down
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseBackward // The "back" side button, also known as button 8
+	MouseForward  // The "forward" side button, also known as button 9
+	MouseButton10 // An extra button, reported by xterm as button 10
+	MouseButton11 // An extra button, reported by xterm as button 11
+)
+
+// mouseButtonNames holds the names returned by MouseButton.String, indexed by button.
+var mouseButtonNames = [...]string{
+	MouseNone:       "none",
+	MouseLeft:       "left",
+	MouseMiddle:     "middle",
+	MouseRight:      "right",
+	MouseWheelUp:    "wheel-up",
+	MouseWheelDown:  "wheel-down",
+	MouseWheelLeft:  "wheel-left",
+	MouseWheelRight: "wheel-right",
+	MouseBackward:   "backward",
+	MouseForward:    "forward",
+	MouseButton10:   "button-10",
+	MouseButton11:   "button-11",
+}
+
+// String returns the lowercase name of the button, such as "left" or "wheel-up".
+func (b MouseButton) String() string {
+	if b < 0 || int(b) >= len(mouseButtonNames) {
+		return "unknown"
+	}
+	return mouseButtonNames[b]
+}
+
+// MouseAction describes what happened to the mouse.
+type MouseAction int
+
+// These constants enumerate the mouse actions.
+const (
+	MousePress   MouseAction = iota // A button was pressed or the wheel was turned
+	MouseRelease                    // A button was released
+	MouseMotion                     // The mouse moved, with or without a button held down
+)
+
+// String returns the lowercase name of the action.
+func (a MouseAction) String() string {
+	switch a {
+	case MousePress:
+		return "press"
+	case MouseRelease:
+		return "release"
+	case MouseMotion:
+		return "motion"
+	default:
+		return "unknown"
+	}
+}
+
+// MouseEvent is a mouse report from a terminal with mouse tracking enabled.
+// Coordinates are zero-based cells, or pixels when the SGR-pixel encoding is enabled.
+type MouseEvent struct {
+	X      int         // Column, or horizontal pixel offset
+	Y      int         // Row, or vertical pixel offset
+	Button MouseButton // The button involved; legacy releases report MouseNone
+	Action MouseAction // Whether the button was pressed, released or moved
+	Mod    Modifier    // The modifiers held down; terminals report Shift, Alt and Ctrl
+}
+
+// event marks MouseEvent as an Event.
+func (MouseEvent) event() {}
+
+// Bits of the button code in a mouse report.
+const (
+	mouseShift  = 4   // Shift was held down
+	mouseAlt    = 8   // Alt (Meta) was held down
+	mouseCtrl   = 16  // Ctrl was held down
+	mouseMotion = 32  // The report is for motion
+	mouseWheel  = 64  // The button is a wheel direction
+	mouseExtra  = 128 // The button is one of the extra buttons
+)
+
+// mouseEvent decodes the button code and zero-based coordinates of a mouse report.
+// The release flag is set for SGR releases; legacy reports encode releases in the button code.
+func mouseEvent(code, x, y int, release bool) MouseEvent {
+	ev := MouseEvent{X: x, Y: y}
+
+	if code&mouseShift != 0 {
+		ev.Mod |= ModShift
+	}
+	if code&mouseAlt != 0 {
+		ev.Mod |= ModAlt
+	}
+	if code&mouseCtrl != 0 {
+		ev.Mod |= ModCtrl
+	}
+
+	low := code & 3
+	switch {
+	case code&mouseExtra != 0:
+		ev.Button = MouseBackward + MouseButton(low)
+	case code&mouseWheel != 0:
+		ev.Button = MouseWheelUp + MouseButton(low)
+	case low == 3:
+		// Legacy encodings report every release, and motion without a button, as button 3.
+		ev.Button = MouseNone
+		if code&mouseMotion == 0 {
+			release = true
+		}
+	default:
+		ev.Button = MouseLeft + MouseButton(low)
+	}
+
+	switch {
+	case release:
+		ev.Action = MouseRelease
+	case code&mouseMotion != 0:
+		ev.Action = MouseMotion
+	default:
+		ev.Action = MousePress
+	}
+	return ev
+}
@@ -0,0 +1,225 @@
+package input
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/droqsic/probe"
+	"github.com/droqsic/probe/platform"
+)
+
+// These constants control how often the reader checks for input and cancellation.
+const (
+	DefaultEscapeTimeout = 50 * time.Millisecond  // Default wait before a lone escape is reported as KeyEscape
+	pollInterval         = 100 * time.Millisecond // How often the read loop checks for context cancellation
+	readBufferSize       = 256                    // Size of the buffer used for each read
+)
+
+// Option configures a Reader.
+type Option func(*Reader)
+
+// WithEscapeTimeout sets how long the reader waits after an escape byte for the rest of a sequence.
+// If no more input arrives within the timeout, the escape is reported as KeyEscape.
+func WithEscapeTimeout(d time.Duration) Option {
+	return func(r *Reader) {
+		r.escapeTimeout = d
+	}
+}
+
+// WithoutRawMode leaves the terminal mode unchanged.
+// Use it when the caller has already put the terminal into raw mode.
+func WithoutRawMode() Option {
+	return func(r *Reader) {
+		r.raw = false
+	}
+}
+
+// WithBracketedPaste turns on bracketed paste mode while events are being read,
+// so that pasted text is delivered as a single PasteEvent. The mode is turned off when reading stops.
+func WithBracketedPaste() Option {
+	return func(r *Reader) {
+		r.paste = true
+	}
+}
+
+// WithMouse turns on mouse tracking with the given encoding while events are being read,
+// so that mouse reports are delivered as MouseEvents. Tracking is turned off when reading stops.
+func WithMouse(tracking probe.MouseTracking, encoding probe.MouseEncoding) Option {
+	return func(r *Reader) {
+		r.mouse = tracking
+		r.mouseEncoding = encoding
+	}
+}
+
+// WithFocus turns on focus reporting while events are being read,
+// so that the terminal gaining or losing focus is delivered as a FocusEvent. Reporting is turned off when reading stops.
+func WithFocus() Option {
+	return func(r *Reader) {
+		r.focus = true
+	}
+}
+
+// WithKittyKeyboard turns on the kitty keyboard protocol with the given flags while events are being read,
+// so that keys are reported unambiguously, with release and repeat events or associated text as requested.
+// The terminal is queried first; if it does not support the protocol, the reader silently keeps using
+// the legacy encodings, which are always decoded. The flags are popped again when reading stops.
+func WithKittyKeyboard(flags probe.KittyKeyboardFlags) Option {
+	return func(r *Reader) {
+		r.kitty = flags
+	}
+}
+
+// Reader reads events from a file descriptor.
+// When the file descriptor is a terminal, it is put into raw mode while events are being read.
+type Reader struct {
+	fd            uintptr                  // File descriptor to read from
+	escapeTimeout time.Duration            // Wait before a lone escape is flushed
+	raw           bool                     // Whether to switch the terminal to raw mode
+	paste         bool                     // Whether to turn on bracketed paste mode
+	mouse         probe.MouseTracking      // Mouse tracking mode to turn on, or zero for none
+	mouseEncoding probe.MouseEncoding      // Encoding of mouse reports
+	focus         bool                     // Whether to turn on focus reporting
+	kitty         probe.KittyKeyboardFlags // Kitty keyboard protocol flags to push, or zero for none
+	decoder       *Decoder                 // Decoder for the byte stream
+
+	mutex sync.Mutex // Protects err
+	err   error      // Error that stopped the read loop
+}
+
+// NewReader returns a Reader for the file descriptor.
+func NewReader(fd uintptr, opts ...Option) *Reader {
+	r := &Reader{
+		fd:            fd,
+		escapeTimeout: DefaultEscapeTimeout,
+		raw:           true,
+		decoder:       NewDecoder(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Events starts reading and returns a channel of decoded events.
+// The channel is closed when ctx is done, the input ends or a read fails; Err reports why.
+// The terminal mode is restored before the channel is closed.
+func (r *Reader) Events(ctx context.Context) <-chan Event {
+	ch := make(chan Event)
+	go r.run(ctx, ch)
+	return ch
+}
+
+// Err returns the error that stopped the reader, or nil if it stopped because ctx was done or the input ended.
+func (r *Reader) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// setErr records the error that stopped the reader.
+func (r *Reader) setErr(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.err = err
+}
+
+// run is the read loop started by Events.
+func (r *Reader) run(ctx context.Context, ch chan<- Event) {
+	defer close(ch)
+
+	if r.raw && probe.IsTerminal(r.fd) {
+		state, err := probe.MakeRaw(r.fd)
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		defer probe.Restore(r.fd, state)
+	}
+
+	if r.paste {
+		restore, err := probe.EnableBracketedPaste(r.fd)
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		defer restore()
+	}
+
+	if r.mouse != 0 {
+		restore, err := probe.EnableMouse(r.fd, r.mouse, r.mouseEncoding)
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		defer restore()
+	}
+
+	if r.focus {
+		restore, err := probe.EnableFocusReporting(r.fd)
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		defer restore()
+	}
+
+	if r.kitty != 0 && probe.IsTerminal(r.fd) {
+		if _, ok, err := probe.QueryKittyKeyboard(ctx, r.fd); err == nil && ok {
+			restore, err := probe.PushKittyKeyboard(r.fd, r.kitty)
+			if err != nil {
+				r.setErr(err)
+				return
+			}
+			defer restore()
+		}
+	}
+
+	buf := make([]byte, readBufferSize)
+	for {
+		timeout := pollInterval
+		if r.decoder.Pending() {
+			timeout = r.escapeTimeout
+		}
+
+		ready, err := platform.Wait(r.fd, timeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.setErr(err)
+			return
+		}
+		if !ready {
+			if r.decoder.Pending() && !send(ctx, ch, r.decoder.Flush()) {
+				return
+			}
+			continue
+		}
+
+		n, err := platform.Read(r.fd, buf)
+		if n > 0 && !send(ctx, ch, r.decoder.Decode(buf[:n])) {
+			return
+		}
+		if n == 0 || err != nil {
+			send(ctx, ch, r.decoder.Flush())
+			if err != nil && err != io.EOF {
+				r.setErr(err)
+			}
+			return
+		}
+	}
+}
+
+// send delivers events in order and reports false if ctx was done first.
+func send(ctx context.Context, ch chan<- Event, events []Event) bool {
+	for _, ev := range events {
+		select {
+		case ch <- ev:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package probe
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// foregroundPollInterval is how often WatchForeground re-checks the foreground process group.
+const foregroundPollInterval = 250 * time.Millisecond
+
+// IsForeground returns true if the current process belongs to the foreground process group of the terminal.
+// It returns false if the file descriptor is not a terminal or the check fails.
+// The result is not cached because it changes whenever the job is moved with fg and bg.
+func IsForeground(fd uintptr) bool {
+	if !IsTerminal(fd) {
+		return false
+	}
+
+	foreground, err := platform.IsForeground(fd)
+	return err == nil && foreground
+}
+
+// IsTostop returns true if the TOSTOP flag is set on the terminal.
+// When it is set, a background job that writes to the terminal is stopped with SIGTTOU,
+// so renderers should stay quiet while IsForeground returns false.
+func IsTostop(fd uintptr) bool {
+	if !IsTerminal(fd) {
+		return false
+	}
+
+	tostop, err := platform.IsTostop(fd)
+	return err == nil && tostop
+}
+
+// WatchForeground reports changes in whether the current process is in the foreground of the terminal.
+// The current state is sent immediately, followed by every change until ctx is done, when the channel is closed.
+// Changes are detected when the process is continued and by polling, so a job moved with fg or bg is noticed promptly.
+func WatchForeground(ctx context.Context, fd uintptr) <-chan bool {
+	ch := make(chan bool, 1)
+
+	resume := make(chan os.Signal, 1)
+	if sigs := platform.ResumeSignals(); len(sigs) > 0 {
+		signal.Notify(resume, sigs...)
+	}
+
+	go func() {
+		defer close(ch)
+		defer signal.Stop(resume)
+
+		ticker := time.NewTicker(foregroundPollInterval)
+		defer ticker.Stop()
+
+		last := IsForeground(fd)
+		ch <- last
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-resume:
+			}
+
+			if current := IsForeground(fd); current != last {
+				last = current
+				select {
+				case ch <- current:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
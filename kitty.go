@@ -0,0 +1,73 @@
+package probe
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// KittyKeyboardFlags is a set of progressive enhancement flags of the kitty keyboard protocol.
+// Each flag makes the terminal report more about the keyboard; the input package decodes the resulting sequences.
+type KittyKeyboardFlags int
+
+// These constants enumerate the progressive enhancement flags.
+const (
+	KittyDisambiguate     KittyKeyboardFlags = 1 << iota // Report ambiguous keys such as Esc and Ctrl+I as distinct escape codes
+	KittyReportEvents                                    // Also report key repeat and release events
+	KittyReportAlternates                                // Also report the shifted and base layout keys
+	KittyReportAllKeys                                   // Report every key as an escape code, including plain text
+	KittyReportText                                      // Report the text a key produces along with it
+)
+
+// QueryKittyKeyboard asks the terminal which kitty keyboard protocol flags are in effect by sending "CSI ? u".
+// It reports false, with a nil error, if the terminal answered the DA1 sentinel but not the query,
+// which means the protocol is not supported and only the legacy encodings will be sent.
+// If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned when nothing answers in time.
+// The file descriptor must be readable and writable, such as os.Stdin attached to a terminal;
+// a *NotTerminalError is returned otherwise.
+func QueryKittyKeyboard(ctx context.Context, fd uintptr) (flags KittyKeyboardFlags, ok bool, err error) {
+	replies, _, err := query(ctx, fd, "\x1b[?u")
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, reply := range replies {
+		if reply.intro != '[' || reply.final != 'u' || !reply.hasPrefix("?") {
+			continue
+		}
+		n, err := strconv.Atoi(reply.body[1:])
+		if err != nil {
+			continue
+		}
+		return KittyKeyboardFlags(n), true, nil
+	}
+	return 0, false, nil
+}
+
+// PushKittyKeyboard pushes flags onto the terminal's stack of kitty keyboard modes by sending "CSI > flags u".
+// It returns a function that pops them again, which should be deferred; with RestoreOnSignal they are also popped
+// if the process receives a termination signal first. Terminals without the protocol ignore the sequence,
+// so callers usually check QueryKittyKeyboard first. Nothing is written when the file descriptor is not a terminal.
+func PushKittyKeyboard(fd uintptr, flags KittyKeyboardFlags) (restore func() error, err error) {
+	return enable(fd,
+		func() error { return writeKitty(fd, '>', int(flags)) },
+		func() error { return writeKitty(fd, '<', 1) },
+	)
+}
+
+// PopKittyKeyboard pops n entries from the terminal's stack of kitty keyboard modes by sending "CSI < n u".
+// Nothing is written when the file descriptor is not a terminal.
+func PopKittyKeyboard(fd uintptr, n int) error {
+	if !IsTerminal(fd) {
+		return nil
+	}
+	return writeKitty(fd, '<', n)
+}
+
+// writeKitty writes a kitty keyboard protocol stack operation, "CSI op n u".
+func writeKitty(fd uintptr, op byte, n int) error {
+	seq := append([]byte{0x1b, '[', op}, strconv.Itoa(n)...)
+	_, err := platform.Write(fd, append(seq, 'u'))
+	return err
+}
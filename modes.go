@@ -0,0 +1,282 @@
+package probe
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Private modes toggled with DECSET and DECRST.
+const (
+	modeCursor         = 25   // Show the text cursor (DECTCEM)
+	modeFocus          = 1004 // Report focus changes as ESC [ I and ESC [ O
+	modeBracketedPaste = 2004 // Wrap pasted text in ESC [ 200 ~ and ESC [ 201 ~
+	modeSynchronized   = 2026 // Hold screen updates until the mode is reset, to draw a frame at once
+)
+
+// MouseTracking selects which mouse events the terminal reports.
+// Its values are the DECSET private mode numbers that enable them.
+type MouseTracking int
+
+// These constants enumerate the mouse tracking modes, from the fewest events reported to the most.
+const (
+	MouseX10      MouseTracking = 9    // Report button presses only
+	MouseNormal   MouseTracking = 1000 // Report button presses and releases, including the wheel
+	MouseButton   MouseTracking = 1002 // Also report motion while a button is held down, for drags
+	MouseAnyEvent MouseTracking = 1003 // Also report motion with no button held down
+)
+
+// MouseEncoding selects how the terminal encodes mouse reports.
+// Its values are the DECSET private mode numbers that enable them, or zero for the legacy encoding.
+type MouseEncoding int
+
+// These constants enumerate the mouse report encodings.
+const (
+	MouseEncodingX10       MouseEncoding = 0    // Legacy encoding as raw bytes, limited to 223 columns and rows
+	MouseEncodingSGR       MouseEncoding = 1006 // SGR encoding with decimal cell coordinates and distinct releases
+	MouseEncodingSGRPixels MouseEncoding = 1016 // SGR encoding with pixel coordinates instead of cells
+)
+
+// mouseModes lists every mouse tracking and encoding mode, for turning them all off.
+var mouseModes = []int{
+	int(MouseX10), int(MouseNormal), int(MouseButton), int(MouseAnyEvent),
+	int(MouseEncodingSGR), int(MouseEncodingSGRPixels),
+}
+
+// mouseModeList returns the private modes that enable tracking with encoding,
+// or ErrUnknownMouseMode if either is not one of the constants.
+func mouseModeList(tracking MouseTracking, encoding MouseEncoding) ([]int, error) {
+	switch tracking {
+	case MouseX10, MouseNormal, MouseButton, MouseAnyEvent:
+	default:
+		return nil, ErrUnknownMouseMode
+	}
+	switch encoding {
+	case MouseEncodingX10:
+		return []int{int(tracking)}, nil
+	case MouseEncodingSGR, MouseEncodingSGRPixels:
+		return []int{int(tracking), int(encoding)}, nil
+	}
+	return nil, ErrUnknownMouseMode
+}
+
+// setPrivateMode writes DECSET (CSI ? mode h) or DECRST (CSI ? mode l) to the terminal.
+func setPrivateMode(fd uintptr, enable bool, modes ...int) error {
+	if len(modes) == 0 {
+		return nil
+	}
+
+	seq := []byte("\x1b[?")
+	for i, mode := range modes {
+		if i > 0 {
+			seq = append(seq, ';')
+		}
+		seq = strconv.AppendInt(seq, int64(mode), 10)
+	}
+	if enable {
+		seq = append(seq, 'h')
+	} else {
+		seq = append(seq, 'l')
+	}
+
+	_, err := platform.Write(fd, seq)
+	return err
+}
+
+// ModeStatus is the state of a private mode as reported by the terminal in a DECRQM reply.
+type ModeStatus int
+
+// These constants enumerate the mode states, with the values used in DECRQM replies.
+const (
+	ModeNotRecognized    ModeStatus = 0 // The terminal does not know the mode
+	ModeSet              ModeStatus = 1 // The mode is on
+	ModeReset            ModeStatus = 2 // The mode is off
+	ModePermanentlySet   ModeStatus = 3 // The mode is always on and cannot be turned off
+	ModePermanentlyReset ModeStatus = 4 // The mode is always off and cannot be turned on
+)
+
+// String returns the name of the status, such as "set" or "not-recognized".
+func (s ModeStatus) String() string {
+	switch s {
+	case ModeSet:
+		return "set"
+	case ModeReset:
+		return "reset"
+	case ModePermanentlySet:
+		return "permanently-set"
+	case ModePermanentlyReset:
+		return "permanently-reset"
+	default:
+		return "not-recognized"
+	}
+}
+
+// Changeable reports whether the terminal recognizes the mode and lets it be turned on and off,
+// which is what callers usually mean by the mode being supported.
+func (s ModeStatus) Changeable() bool {
+	return s == ModeSet || s == ModeReset
+}
+
+// QueryMode asks the terminal for the state of a private mode with DECRQM ("CSI ? mode $ p"),
+// for example 2004 for bracketed paste, 1004 for focus reporting, 2026 for synchronized output
+// or 2027 for grapheme clustering. Terminals that do not implement DECRQM report every mode
+// as ModeNotRecognized. If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned
+// when nothing answers in time. The file descriptor must be readable and writable;
+// a *NotTerminalError is returned otherwise.
+func QueryMode(ctx context.Context, fd uintptr, mode int) (ModeStatus, error) {
+	statuses, err := QueryModes(ctx, fd, mode)
+	if err != nil {
+		return ModeNotRecognized, err
+	}
+	return statuses[mode], nil
+}
+
+// QueryModes asks for the state of several private modes in one round trip, as QueryMode does for one.
+// The result has an entry for every mode, which is ModeNotRecognized for those the terminal did not answer.
+func QueryModes(ctx context.Context, fd uintptr, modes ...int) (map[int]ModeStatus, error) {
+	var request strings.Builder
+	for _, mode := range modes {
+		request.WriteString("\x1b[?" + strconv.Itoa(mode) + "$p")
+	}
+
+	replies, _, err := query(ctx, fd, request.String())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int]ModeStatus, len(modes))
+	for _, mode := range modes {
+		statuses[mode] = ModeNotRecognized
+	}
+	for _, reply := range replies {
+		// "CSI ? mode ; value $ y"; the '$' intermediate byte ends up in the body.
+		if reply.intro != '[' || reply.final != 'y' || !reply.hasPrefix("?") || !strings.HasSuffix(reply.body, "$") {
+			continue
+		}
+		reply.body = reply.body[:len(reply.body)-1]
+		p := reply.params()
+		if len(p) != 2 || p[1] < 0 || p[1] > int(ModePermanentlyReset) {
+			continue
+		}
+		if _, ok := statuses[p[0]]; ok {
+			statuses[p[0]] = ModeStatus(p[1])
+		}
+	}
+	return statuses, nil
+}
+
+// enableModes turns on private modes and returns a function that turns them off again.
+// With RestoreOnSignal, the modes are also turned off if the process receives a termination signal while they are on.
+// Nothing is written when the file descriptor is not a terminal.
+func enableModes(fd uintptr, modes ...int) (func() error, error) {
+	return enable(fd,
+		func() error { return setPrivateMode(fd, true, modes...) },
+		func() error { return setPrivateMode(fd, false, modes...) },
+	)
+}
+
+// enable runs set and returns a function that runs reset once, undoing it.
+// With RestoreOnSignal, reset also runs if the process receives a termination signal before the returned function is called.
+// Neither runs when the file descriptor is not a terminal.
+func enable(fd uintptr, set, reset func() error) (func() error, error) {
+	if !IsTerminal(fd) {
+		return func() error { return nil }, nil
+	}
+
+	if err := set(); err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	var err error
+	undo := func() {
+		once.Do(func() { err = reset() })
+	}
+	stop := restoreOnSignal(undo)
+
+	return func() error {
+		stop()
+		undo()
+		return err
+	}, nil
+}
+
+// disableModes turns off private modes, writing nothing when the file descriptor is not a terminal.
+func disableModes(fd uintptr, modes ...int) error {
+	if !IsTerminal(fd) {
+		return nil
+	}
+	return setPrivateMode(fd, false, modes...)
+}
+
+// EnableBracketedPaste turns on bracketed paste mode (DECSET 2004).
+// While it is on, the terminal wraps pasted text in markers so that a multi-line paste
+// is not mistaken for typed commands; the input package decodes it as a single PasteEvent.
+// It returns a function that turns the mode off again, which should be deferred.
+// Nothing is written when the file descriptor is not a terminal.
+func EnableBracketedPaste(fd uintptr) (restore func() error, err error) {
+	return enableModes(fd, modeBracketedPaste)
+}
+
+// DisableBracketedPaste turns off bracketed paste mode (DECRST 2004).
+// Nothing is written when the file descriptor is not a terminal.
+func DisableBracketedPaste(fd uintptr) error {
+	return disableModes(fd, modeBracketedPaste)
+}
+
+// EnableMouse turns on mouse tracking with the given report encoding.
+// The input package decodes the reports into MouseEvents.
+// It returns a function that turns tracking off again, which should be deferred.
+// Nothing is written when the file descriptor is not a terminal, so no reports end up in pipes or files.
+// It returns ErrUnknownMouseMode if tracking or encoding is not one of the constants.
+func EnableMouse(fd uintptr, tracking MouseTracking, encoding MouseEncoding) (restore func() error, err error) {
+	modes, err := mouseModeList(tracking, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return enableModes(fd, modes...)
+}
+
+// DisableMouse turns off every mouse tracking mode and encoding.
+// Nothing is written when the file descriptor is not a terminal.
+func DisableMouse(fd uintptr) error {
+	return disableModes(fd, mouseModes...)
+}
+
+// EnableFocusReporting turns on focus reporting (DECSET 1004).
+// While it is on, the terminal reports when its window gains or loses focus;
+// the input package decodes the reports into FocusEvents.
+// It returns a function that turns reporting off again, which should be deferred.
+// Nothing is written when the file descriptor is not a terminal.
+func EnableFocusReporting(fd uintptr) (restore func() error, err error) {
+	return enableModes(fd, modeFocus)
+}
+
+// DisableFocusReporting turns off focus reporting (DECRST 1004).
+// Nothing is written when the file descriptor is not a terminal.
+func DisableFocusReporting(fd uintptr) error {
+	return disableModes(fd, modeFocus)
+}
+
+// HideCursor hides the text cursor (DECRST 25), as full-screen programs do while redrawing.
+// It returns a function that shows the cursor again, which should be deferred;
+// with RestoreOnSignal, the cursor is also shown if the process receives a termination signal first.
+// Nothing is written when the file descriptor is not a terminal.
+func HideCursor(fd uintptr) (restore func() error, err error) {
+	return enable(fd,
+		func() error { return setPrivateMode(fd, false, modeCursor) },
+		func() error { return setPrivateMode(fd, true, modeCursor) },
+	)
+}
+
+// ShowCursor shows the text cursor (DECSET 25).
+// Nothing is written when the file descriptor is not a terminal.
+func ShowCursor(fd uintptr) error {
+	if !IsTerminal(fd) {
+		return nil
+	}
+	return setPrivateMode(fd, true, modeCursor)
+}
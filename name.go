@@ -0,0 +1,28 @@
+package probe
+
+import (
+	"github.com/droqsic/probe/platform"
+)
+
+// TerminalName returns the device path of the terminal behind the file descriptor,
+// such as /dev/pts/3, /dev/ttyS0 or /dev/tty1.
+// It returns a *NotTerminalError if the file descriptor is not a terminal.
+// Successful lookups are cached; this function is thread-safe and can be called from multiple goroutines.
+func TerminalName(fd uintptr) (string, error) {
+	// Check cache first to avoid scanning the device directories again.
+	if result, ok := getCache(cache.name, fd); ok {
+		return result, nil
+	}
+
+	if !IsTerminal(fd) {
+		return "", &NotTerminalError{Fd: fd}
+	}
+
+	result, err := platform.Name(fd)
+	if err != nil {
+		return "", err
+	}
+
+	setCache(cache.name, fd, result)
+	return result, nil
+}
@@ -0,0 +1,226 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// pollInterval is how often blocking reads check for context cancellation.
+const pollInterval = 50 * time.Millisecond
+
+// Control characters recognized while reading a password.
+const (
+	keyInterrupt = 0x03 // Ctrl-C
+	keyEOF       = 0x04 // Ctrl-D
+	keyBackspace = 0x08 // Ctrl-H
+	keyKillLine  = 0x15 // Ctrl-U
+	keyDelete    = 0x7f // DEL, sent by most terminals for backspace
+)
+
+// PasswordOption configures ReadPassword and ReadPasswordContext.
+type PasswordOption func(*passwordOptions)
+
+// passwordOptions holds the settings applied by PasswordOption values.
+type passwordOptions struct {
+	mask             rune    // Character echoed for each typed character, or zero for none
+	output           uintptr // File descriptor the mask is written to
+	hasOutput        bool    // Whether output was set, rather than defaulting to the input
+	allowNonTerminal bool    // Whether non-terminal file descriptors are read as plain lines
+}
+
+// WithMask echoes mask for every typed character, for example '*'.
+// The mask is written to the file descriptor that input is read from, unless WithOutput names another.
+func WithMask(mask rune) PasswordOption {
+	return func(o *passwordOptions) {
+		o.mask = mask
+	}
+}
+
+// WithOutput writes the mask to fd instead of the input file descriptor.
+// It is needed on Windows, where the console input handle cannot be written to:
+//
+//	password, err := probe.ReadPassword(os.Stdin.Fd(), probe.WithMask('*'), probe.WithOutput(os.Stdout.Fd()))
+func WithOutput(fd uintptr) PasswordOption {
+	return func(o *passwordOptions) {
+		o.output, o.hasOutput = fd, true
+	}
+}
+
+// AllowNonTerminal permits reading from a file descriptor that is not a terminal, such as a pipe.
+// Without it, ReadPassword returns a *NotTerminalError for such descriptors.
+func AllowNonTerminal() PasswordOption {
+	return func(o *passwordOptions) {
+		o.allowNonTerminal = true
+	}
+}
+
+// ReadPassword reads a line of input from a terminal with echo disabled.
+// The returned slice does not include the line terminator; call Wipe on it once it is no longer needed.
+// Ctrl-C does not raise SIGINT while the password is read; ReadPassword returns ErrInterrupted instead.
+// The terminal state is restored on return, on panic, and, with RestoreOnSignal, when the process receives
+// a termination signal.
+func ReadPassword(fd uintptr, opts ...PasswordOption) ([]byte, error) {
+	return ReadPasswordContext(context.Background(), fd, opts...)
+}
+
+// ReadPasswordContext is like ReadPassword but stops reading when ctx is cancelled.
+// Backspace removes the last character and Ctrl-U clears the whole line.
+// If the mask cannot be written, the error is returned and nothing is read further.
+func ReadPasswordContext(ctx context.Context, fd uintptr, opts ...PasswordOption) ([]byte, error) {
+	var o passwordOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.hasOutput {
+		o.output = fd
+	}
+
+	if !IsTerminal(fd) {
+		if !o.allowNonTerminal {
+			return nil, &NotTerminalError{Fd: fd}
+		}
+		o.mask = 0
+		return readPassword(ctx, fd, o)
+	}
+
+	old, err := platform.DisableEcho(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() { _ = platform.SetState(fd, old) })
+	}
+	defer restore()
+
+	stop := restoreOnSignal(func() {
+		restore()
+		cancel(ErrInterrupted)
+	})
+	defer stop()
+
+	return readPassword(ctx, fd, o)
+}
+
+// readPassword reads bytes one at a time until a line terminator, applying line editing.
+func readPassword(ctx context.Context, fd uintptr, o passwordOptions) ([]byte, error) {
+	var mask []byte
+	if o.mask != 0 {
+		mask = utf8.AppendRune(nil, o.mask)
+	}
+
+	buf := make([]byte, 0, 64)
+	var b [1]byte
+	for {
+		if err := waitInput(ctx, fd); err != nil {
+			Wipe(buf)
+			return nil, err
+		}
+
+		n, err := platform.Read(fd, b[:])
+		if n == 0 {
+			if err == nil || err == io.EOF {
+				if len(buf) > 0 {
+					return buf, nil
+				}
+				err = io.EOF
+			}
+			Wipe(buf)
+			return nil, err
+		}
+
+		switch c := b[0]; c {
+		case '\r', '\n':
+			return buf, nil
+		case keyInterrupt:
+			Wipe(buf)
+			return nil, ErrInterrupted
+		case keyEOF:
+			if len(buf) == 0 {
+				return nil, io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if len(buf) > 0 {
+				_, size := utf8.DecodeLastRune(buf)
+				Wipe(buf[len(buf)-size:])
+				buf = buf[:len(buf)-size]
+				if err := erase(o.output, mask, 1); err != nil {
+					Wipe(buf)
+					return nil, err
+				}
+			}
+		case keyKillLine:
+			err := erase(o.output, mask, utf8.RuneCount(buf))
+			Wipe(buf)
+			buf = buf[:0]
+			if err != nil {
+				return nil, err
+			}
+		default:
+			if c < ' ' && c != '\t' {
+				continue
+			}
+			if len(buf) == cap(buf) {
+				grown := make([]byte, len(buf), 2*cap(buf))
+				copy(grown, buf)
+				Wipe(buf)
+				buf = grown
+			}
+			buf = append(buf, c)
+			if mask != nil && utf8.RuneStart(c) {
+				if _, err := platform.Write(o.output, mask); err != nil {
+					Wipe(buf)
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+// erase removes count mask characters from the terminal by backspacing over them.
+func erase(fd uintptr, mask []byte, count int) error {
+	if mask == nil || count == 0 {
+		return nil
+	}
+	_, err := platform.Write(fd, []byte(strings.Repeat("\b \b", count)))
+	return err
+}
+
+// waitInput blocks until the file descriptor has input available or ctx is done.
+// It returns the cancellation cause of ctx when it is done first.
+func waitInput(ctx context.Context, fd uintptr) error {
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		timeout := pollInterval
+		if ctx.Done() == nil {
+			timeout = -1
+		}
+		ready, err := platform.Wait(fd, timeout)
+		if err != nil {
+			return err
+		}
+		if ready {
+			return nil
+		}
+	}
+}
+
+// Wipe overwrites b with zeros.
+// Use it to clear a password returned by ReadPassword once it is no longer needed.
+func Wipe(b []byte) {
+	clear(b)
+	runtime.KeepAlive(b)
+}
@@ -1,5 +1,59 @@
 package platform
 
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrNotSupported is returned when an operation is not available on the current platform.
+var ErrNotSupported = errors.New("platform: operation not supported")
+
+// State holds a saved terminal state for a file descriptor.
+// Its contents are platform-specific and it can only be restored with SetState.
+type State struct {
+	state
+}
+
+// Attributes is a portable decoding of the line discipline settings of a terminal.
+// On Unix-like systems it is derived from the termios or termio structure, and on Windows from the console mode.
+type Attributes struct {
+	Echo             bool        `json:"echo"`              // ECHO: typed characters are echoed back
+	Canonical        bool        `json:"canonical"`         // ICANON: input is line-buffered with editing
+	Signals          bool        `json:"signals"`           // ISIG: the interrupt, quit and suspend characters raise signals
+	FlowControl      bool        `json:"flow_control"`      // IXON: Ctrl-S and Ctrl-Q stop and start output
+	OutputProcessing bool        `json:"output_processing"` // OPOST: output is post-processed
+	MapNewline       bool        `json:"map_newline"`       // ONLCR: newline is translated to carriage return and newline on output
+	InputSpeed       uint32      `json:"input_speed"`       // Input baud rate, or zero if unknown
+	OutputSpeed      uint32      `json:"output_speed"`      // Output baud rate, or zero if unknown
+	Interrupt        ControlChar `json:"interrupt"`         // VINTR: the character that raises SIGINT
+	EOF              ControlChar `json:"eof"`               // VEOF: the character that signals end of input
+	Erase            ControlChar `json:"erase"`             // VERASE: the character that erases the previous character
+}
+
+// ControlChar is a special input character of the line discipline.
+// The zero value means the character is disabled.
+type ControlChar byte
+
+// String returns the control character in caret notation, such as ^C or ^?.
+func (c ControlChar) String() string {
+	switch {
+	case c == 0:
+		return "<undef>"
+	case c == 0x7f:
+		return "^?"
+	case c < 0x20:
+		return "^" + string(rune(c+'@'))
+	default:
+		return string(rune(c))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler using caret notation.
+func (c ControlChar) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
 // IsTerminal returns true if the given file descriptor is a terminal.
 // This function is implemented differently for each platform.
 func IsTerminal(fd uintptr) bool {
@@ -11,3 +65,115 @@ func IsTerminal(fd uintptr) bool {
 func IsCygwin(fd uintptr) bool {
 	return isCygwin(fd)
 }
+
+// Name returns the device path of the terminal behind the file descriptor, such as /dev/pts/3.
+func Name(fd uintptr) (string, error) {
+	return name(fd)
+}
+
+// DeviceNumber returns the major and minor device numbers of the character device behind the file descriptor.
+func DeviceNumber(fd uintptr) (major, minor uint32, err error) {
+	return deviceNumber(fd)
+}
+
+// GetAttributes returns the decoded line discipline settings of the terminal.
+func GetAttributes(fd uintptr) (*Attributes, error) {
+	return getAttributes(fd)
+}
+
+// GetState returns the current terminal state of the given file descriptor.
+// The returned state can be passed to SetState to restore it later.
+func GetState(fd uintptr) (*State, error) {
+	return getState(fd)
+}
+
+// SetState restores a terminal state previously returned by GetState or DisableEcho.
+func SetState(fd uintptr, s *State) error {
+	return setState(fd, s)
+}
+
+// DisableEcho turns off input echo, line editing and signal generation, so that Ctrl-C is read as a byte.
+// It returns the previous state so that it can be restored with SetState.
+func DisableEcho(fd uintptr) (*State, error) {
+	return disableEcho(fd)
+}
+
+// MakeRaw puts the terminal into raw mode, where input is delivered byte by byte
+// without echo, line editing, signal generation or input translation.
+// It returns the previous state so that it can be restored with SetState.
+func MakeRaw(fd uintptr) (*State, error) {
+	return makeRaw(fd)
+}
+
+// WindowSize is the size of a terminal window in character cells and, where the terminal reports it, in pixels.
+type WindowSize struct {
+	Columns int `json:"columns"` // Width in character cells
+	Rows    int `json:"rows"`    // Height in character cells
+	Width   int `json:"width"`   // Width of the text area in pixels, or zero if unknown
+	Height  int `json:"height"`  // Height of the text area in pixels, or zero if unknown
+}
+
+// GetWindowSize returns the size of the terminal window.
+func GetWindowSize(fd uintptr) (WindowSize, error) {
+	return getWindowSize(fd)
+}
+
+// Read reads up to len(p) bytes from the file descriptor without taking ownership of it.
+func Read(fd uintptr, p []byte) (int, error) {
+	return read(fd, p)
+}
+
+// Write writes p to the file descriptor without taking ownership of it.
+func Write(fd uintptr, p []byte) (int, error) {
+	return write(fd, p)
+}
+
+// Wait blocks until the file descriptor has input available or the timeout elapses.
+// It returns true if input is available. A negative timeout waits indefinitely.
+func Wait(fd uintptr, timeout time.Duration) (bool, error) {
+	return wait(fd, timeout)
+}
+
+// IsForeground returns true if the current process group is the foreground process group of the terminal.
+// Platforms without job control report every terminal as foreground.
+func IsForeground(fd uintptr) (bool, error) {
+	return isForeground(fd)
+}
+
+// IsTostop returns true if the TOSTOP flag is set on the terminal.
+// When it is set, background processes that write to the terminal are stopped with SIGTTOU.
+func IsTostop(fd uintptr) (bool, error) {
+	return isTostop(fd)
+}
+
+// ResumeSignals returns the signals delivered when a stopped process is continued, such as SIGCONT.
+// It returns nil on platforms without job control.
+func ResumeSignals() []os.Signal {
+	return resumeSignals()
+}
+
+// SuspendSignals returns the signals that stop the process from the terminal, such as SIGTSTP.
+// It returns nil on platforms without job control.
+func SuspendSignals() []os.Signal {
+	return suspendSignals()
+}
+
+// TerminationSignals returns the signals that terminate the process by default.
+// Callers that change terminal state listen for these to restore it before exiting.
+func TerminationSignals() []os.Signal {
+	return terminationSignals()
+}
+
+// Raise delivers the signal to the current process again.
+// It is used after restoring terminal state so that the default action still takes place.
+// It returns ErrNotSupported where a process cannot signal itself, as on Windows.
+func Raise(sig os.Signal) error {
+	return raise(sig)
+}
+
+// Suspend stops the current process until it is continued, as the default action of SIGTSTP would.
+// It is used after restoring terminal state in response to a suspend signal, which once caught
+// can no longer take its default action. It returns ErrNotSupported on platforms without job control.
+func Suspend() error {
+	return suspend()
+}
@@ -7,6 +7,23 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// Termios ioctl requests used on AIX.
+// TCGETA only fills the shorter termio structure, so the full termios requests are used for state changes.
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
+
+// vdisable is the value of a disabled control character on AIX.
+const vdisable = 0xff
+
+// baudRates maps the speed codes stored in the termios control flags to baud rates.
+var baudRates = map[uint32]uint32{
+	unix.B50: 50, unix.B75: 75, unix.B110: 110, unix.B134: 134, unix.B150: 150, unix.B200: 200,
+	unix.B300: 300, unix.B600: 600, unix.B1200: 1200, unix.B1800: 1800, unix.B2400: 2400,
+	unix.B4800: 4800, unix.B9600: 9600, unix.B19200: 19200, unix.B38400: 38400,
+}
+
 // isTerminal returns true if the given file descriptor is a terminal on AIX.
 // It uses the TCGETA ioctl call which is specific to AIX.
 func isTerminal(fd uintptr) bool {
@@ -19,3 +36,13 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// termiosSpeed decodes the input and output baud rates from the CBAUD and CIBAUD control flags.
+func termiosSpeed(t *unix.Termios) (uint32, uint32) {
+	out := baudRates[t.Cflag&unix.CBAUD]
+	in := out
+	if code := (t.Cflag & unix.CIBAUD) >> unix.IBSHIFT; code != 0 {
+		in = baudRates[code]
+	}
+	return in, out
+}
@@ -4,9 +4,14 @@
 package platform
 
 import (
+	"os"
 	"syscall"
+	"time"
 )
 
+// state is empty on Plan9 because console modes are controlled through /dev/consctl.
+type state struct{}
+
 // isTerminal returns true if the given file descriptor is a terminal on Plan9.
 // In Plan9, terminals are represented by specific device paths.
 func isTerminal(fd uintptr) bool {
@@ -22,3 +27,99 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// name returns the path of the file descriptor using Fd2path.
+func name(fd uintptr) (string, error) {
+	return syscall.Fd2path(int(fd))
+}
+
+// deviceNumber is not supported on Plan9, which has no device numbers.
+func deviceNumber(fd uintptr) (uint32, uint32, error) {
+	return 0, 0, ErrNotSupported
+}
+
+// getAttributes is not supported on Plan9.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	return nil, ErrNotSupported
+}
+
+// getState is not supported on Plan9.
+func getState(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// setState is not supported on Plan9.
+func setState(fd uintptr, s *State) error {
+	return ErrNotSupported
+}
+
+// disableEcho is not supported on Plan9.
+func disableEcho(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// makeRaw is not supported on Plan9.
+func makeRaw(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// getWindowSize is not supported on Plan9.
+func getWindowSize(fd uintptr) (WindowSize, error) {
+	return WindowSize{}, ErrNotSupported
+}
+
+// read reads from the file descriptor using the Plan9 read system call.
+func read(fd uintptr, p []byte) (int, error) {
+	return syscall.Read(int(fd), p)
+}
+
+// write writes to the file descriptor using the Plan9 write system call.
+func write(fd uintptr, p []byte) (int, error) {
+	return syscall.Write(int(fd), p)
+}
+
+// wait always reports input as available on Plan9, which has no poll system call.
+// Subsequent reads block until data arrives.
+func wait(fd uintptr, timeout time.Duration) (bool, error) {
+	return true, nil
+}
+
+// isForeground reports whether the file descriptor is a terminal.
+// Plan9 has no job control, so a terminal is always in the foreground.
+func isForeground(fd uintptr) (bool, error) {
+	return isTerminal(fd), nil
+}
+
+// isTostop is not supported on Plan9.
+func isTostop(fd uintptr) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// resumeSignals returns no signals because there is no job control on Plan9.
+func resumeSignals() []os.Signal {
+	return nil
+}
+
+// suspendSignals returns no signals because there is no job control on Plan9.
+func suspendSignals() []os.Signal {
+	return nil
+}
+
+// terminationSignals returns the interrupt note, the only note a Plan9 console delivers.
+func terminationSignals() []os.Signal {
+	return []os.Signal{os.Interrupt}
+}
+
+// raise posts the note to the current process.
+func raise(sig os.Signal) error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
+
+// suspend is not supported because there is no job control on Plan9.
+func suspend() error {
+	return ErrNotSupported
+}
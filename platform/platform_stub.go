@@ -3,6 +3,14 @@
 
 package platform
 
+import (
+	"os"
+	"time"
+)
+
+// state is empty on unsupported platforms.
+type state struct{}
+
 // isTerminal is a stub implementation for unsupported platforms.
 // It always returns false.
 func isTerminal(fd uintptr) bool {
@@ -14,3 +22,111 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// name is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func name(fd uintptr) (string, error) {
+	return "", ErrNotSupported
+}
+
+// deviceNumber is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func deviceNumber(fd uintptr) (uint32, uint32, error) {
+	return 0, 0, ErrNotSupported
+}
+
+// getAttributes is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	return nil, ErrNotSupported
+}
+
+// getState is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func getState(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// setState is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func setState(fd uintptr, s *State) error {
+	return ErrNotSupported
+}
+
+// disableEcho is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func disableEcho(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// makeRaw is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func makeRaw(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// getWindowSize is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func getWindowSize(fd uintptr) (WindowSize, error) {
+	return WindowSize{}, ErrNotSupported
+}
+
+// read is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func read(fd uintptr, p []byte) (int, error) {
+	return 0, ErrNotSupported
+}
+
+// write is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func write(fd uintptr, p []byte) (int, error) {
+	return 0, ErrNotSupported
+}
+
+// wait is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func wait(fd uintptr, timeout time.Duration) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// isForeground is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func isForeground(fd uintptr) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// isTostop is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func isTostop(fd uintptr) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// resumeSignals is a stub implementation for unsupported platforms.
+// It always returns no signals.
+func resumeSignals() []os.Signal {
+	return nil
+}
+
+// suspendSignals is a stub implementation for unsupported platforms.
+// It always returns no signals.
+func suspendSignals() []os.Signal {
+	return nil
+}
+
+// terminationSignals is a stub implementation for unsupported platforms.
+// It always returns no signals.
+func terminationSignals() []os.Signal {
+	return nil
+}
+
+// raise is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func raise(sig os.Signal) error {
+	return ErrNotSupported
+}
+
+// suspend is a stub implementation for unsupported platforms.
+// It always returns ErrNotSupported.
+func suspend() error {
+	return ErrNotSupported
+}
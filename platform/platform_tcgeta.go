@@ -19,3 +19,104 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// baudRates maps the speed codes stored in the termio control flags to baud rates.
+var baudRates = map[uint16]uint32{
+	unix.B50: 50, unix.B75: 75, unix.B110: 110, unix.B134: 134, unix.B150: 150, unix.B200: 200,
+	unix.B300: 300, unix.B600: 600, unix.B1200: 1200, unix.B1800: 1800, unix.B2400: 2400,
+	unix.B4800: 4800, unix.B9600: 9600, unix.B19200: 19200, unix.B38400: 38400,
+}
+
+// state holds the termio structure of a terminal on Solaris, Illumos, and Haikou.
+type state struct {
+	termio unix.Termio
+}
+
+// getState reads the termio structure of the file descriptor using TCGETA.
+func getState(fd uintptr) (*State, error) {
+	termio, err := unix.IoctlGetTermio(int(fd), unix.TCGETA)
+	if err != nil {
+		return nil, err
+	}
+	return &State{state{termio: *termio}}, nil
+}
+
+// setState writes the saved termio structure back to the file descriptor using TCSETA.
+func setState(fd uintptr, s *State) error {
+	termio := s.termio
+	return unix.IoctlSetTermio(int(fd), unix.TCSETA, &termio)
+}
+
+// disableEcho clears ECHO and ICANON so input is delivered byte by byte without being displayed.
+// ISIG is cleared as well, so that Ctrl-C is read as a byte instead of raising SIGINT.
+func disableEcho(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	termio := old.termio
+	termio.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG
+	termio.Cc[unix.VMIN] = 1
+	termio.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermio(int(fd), unix.TCSETA, &termio); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// isTostop reports whether TOSTOP is set in the local flags of the termio structure.
+func isTostop(fd uintptr) (bool, error) {
+	s, err := getState(fd)
+	if err != nil {
+		return false, err
+	}
+	return s.termio.Lflag&unix.TOSTOP != 0, nil
+}
+
+// getAttributes decodes the termio structure of the file descriptor.
+// The termio structure has a single speed field, limited to 38400 baud.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	s, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &s.termio
+	speed := baudRates[t.Cflag&unix.CBAUD]
+
+	return &Attributes{
+		Echo:             t.Lflag&unix.ECHO != 0,
+		Canonical:        t.Lflag&unix.ICANON != 0,
+		Signals:          t.Lflag&unix.ISIG != 0,
+		FlowControl:      t.Iflag&unix.IXON != 0,
+		OutputProcessing: t.Oflag&unix.OPOST != 0,
+		MapNewline:       t.Oflag&unix.ONLCR != 0,
+		InputSpeed:       speed,
+		OutputSpeed:      speed,
+		Interrupt:        ControlChar(t.Cc[unix.VINTR]),
+		EOF:              ControlChar(t.Cc[unix.VEOF]),
+		Erase:            ControlChar(t.Cc[unix.VERASE]),
+	}, nil
+}
+
+// makeRaw applies the same settings as cfmakeraw(3) to the termio structure.
+func makeRaw(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	termio := old.termio
+	termio.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termio.Oflag &^= unix.OPOST
+	termio.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termio.Cflag &^= unix.CSIZE | unix.PARENB
+	termio.Cflag |= unix.CS8
+	termio.Cc[unix.VMIN] = 1
+	termio.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermio(int(fd), unix.TCSETA, &termio); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
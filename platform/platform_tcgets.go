@@ -7,6 +7,26 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// Termios ioctl requests used on Linux and Android.
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
+
+// vdisable is the value of a disabled control character on Linux and Android.
+const vdisable = 0
+
+// baudRates maps the speed codes stored in the termios control flags to baud rates.
+var baudRates = map[uint32]uint32{
+	unix.B50: 50, unix.B75: 75, unix.B110: 110, unix.B134: 134, unix.B150: 150, unix.B200: 200,
+	unix.B300: 300, unix.B600: 600, unix.B1200: 1200, unix.B1800: 1800, unix.B2400: 2400,
+	unix.B4800: 4800, unix.B9600: 9600, unix.B19200: 19200, unix.B38400: 38400,
+	unix.B57600: 57600, unix.B115200: 115200, unix.B230400: 230400, unix.B460800: 460800,
+	unix.B500000: 500000, unix.B576000: 576000, unix.B921600: 921600, unix.B1000000: 1000000,
+	unix.B1152000: 1152000, unix.B1500000: 1500000, unix.B2000000: 2000000,
+	unix.B2500000: 2500000, unix.B3000000: 3000000, unix.B3500000: 3500000, unix.B4000000: 4000000,
+}
+
 // isTerminal returns true if the given file descriptor is a terminal on Linux or Android.
 // It uses the TCGETS ioctl call which is specific to Linux and Android.
 func isTerminal(fd uintptr) bool {
@@ -19,3 +39,14 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// termiosSpeed decodes the input and output baud rates from the CBAUD and CIBAUD control flags.
+// An input speed of zero means it is the same as the output speed.
+func termiosSpeed(t *unix.Termios) (uint32, uint32) {
+	out := baudRates[uint32(t.Cflag&unix.CBAUD)]
+	in := out
+	if code := uint32(t.Cflag&unix.CIBAUD) >> unix.IBSHIFT; code != 0 {
+		in = baudRates[code]
+	}
+	return in, out
+}
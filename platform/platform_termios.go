@@ -0,0 +1,108 @@
+//go:build linux || android || darwin || freebsd || openbsd || netbsd || dragonfly || hurd || aix || zos || ios
+// +build linux android darwin freebsd openbsd netbsd dragonfly hurd aix zos ios
+
+package platform
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// state holds the termios structure of a terminal on systems that support termios.
+type state struct {
+	termios unix.Termios
+}
+
+// getState reads the termios structure of the file descriptor.
+func getState(fd uintptr) (*State, error) {
+	termios, err := unix.IoctlGetTermios(int(fd), ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+	return &State{state{termios: *termios}}, nil
+}
+
+// setState writes the saved termios structure back to the file descriptor.
+func setState(fd uintptr, s *State) error {
+	termios := s.termios
+	return unix.IoctlSetTermios(int(fd), ioctlSetTermios, &termios)
+}
+
+// disableEcho clears ECHO and ICANON so input is delivered byte by byte without being displayed.
+// ISIG is cleared as well, so that Ctrl-C is read as a byte instead of raising SIGINT.
+func disableEcho(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	termios := old.termios
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(fd), ioctlSetTermios, &termios); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// isTostop reports whether TOSTOP is set in the local flags of the termios structure.
+func isTostop(fd uintptr) (bool, error) {
+	s, err := getState(fd)
+	if err != nil {
+		return false, err
+	}
+	return s.termios.Lflag&unix.TOSTOP != 0, nil
+}
+
+// getAttributes decodes the termios structure of the file descriptor.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	s, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &s.termios
+	in, out := termiosSpeed(t)
+	return &Attributes{
+		Echo:             t.Lflag&unix.ECHO != 0,
+		Canonical:        t.Lflag&unix.ICANON != 0,
+		Signals:          t.Lflag&unix.ISIG != 0,
+		FlowControl:      t.Iflag&unix.IXON != 0,
+		OutputProcessing: t.Oflag&unix.OPOST != 0,
+		MapNewline:       t.Oflag&unix.ONLCR != 0,
+		InputSpeed:       in,
+		OutputSpeed:      out,
+		Interrupt:        controlChar(t.Cc[unix.VINTR]),
+		EOF:              controlChar(t.Cc[unix.VEOF]),
+		Erase:            controlChar(t.Cc[unix.VERASE]),
+	}, nil
+}
+
+// controlChar converts a control character slot, mapping the platform's disabled value to zero.
+func controlChar(c uint8) ControlChar {
+	if c == vdisable {
+		return 0
+	}
+	return ControlChar(c)
+}
+
+// makeRaw applies the same settings as cfmakeraw(3).
+func makeRaw(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	termios := old.termios
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(fd), ioctlSetTermios, &termios); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
@@ -7,6 +7,15 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// Termios ioctl requests used on BSD systems.
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
+
+// vdisable is the value of a disabled control character on BSD systems.
+const vdisable = 0xff
+
 // isTerminal returns true if the given file descriptor is a terminal on BSD systems.
 // It uses the TIOCGETA ioctl call which is common across BSD variants.
 func isTerminal(fd uintptr) bool {
@@ -19,3 +28,8 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// termiosSpeed returns the input and output baud rates, which BSD systems store directly in the termios structure.
+func termiosSpeed(t *unix.Termios) (uint32, uint32) {
+	return uint32(t.Ispeed), uint32(t.Ospeed)
+}
@@ -0,0 +1,176 @@
+//go:build linux || android || darwin || freebsd || openbsd || netbsd || dragonfly || hurd || solaris || aix || illumos || zos || ios || haikou
+// +build linux android darwin freebsd openbsd netbsd dragonfly hurd solaris aix illumos zos ios haikou
+
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// deviceDirs lists the directories searched for a terminal device node, in order.
+var deviceDirs = []string{"/dev/pts", "/dev"}
+
+// name resolves the device path of the terminal.
+// It first follows the /proc/self/fd symlink, which is available on Linux, and falls back to
+// scanning the device directories for a character device with the same device number.
+func name(fd uintptr) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(fd), &st); err != nil {
+		return "", err
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFCHR {
+		return "", unix.ENOTTY
+	}
+	rdev := uint64(st.Rdev)
+
+	if path, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(int(fd))); err == nil && isDevice(path, rdev) {
+		return path, nil
+	}
+
+	for _, dir := range deviceDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeCharDevice == 0 {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isDevice(path, rdev) {
+				return path, nil
+			}
+		}
+	}
+
+	return "", unix.ENOENT
+}
+
+// deviceNumber splits the device number reported by fstat(2) into its major and minor parts.
+func deviceNumber(fd uintptr) (uint32, uint32, error) {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(fd), &st); err != nil {
+		return 0, 0, err
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFCHR {
+		return 0, 0, unix.ENOTTY
+	}
+	rdev := uint64(st.Rdev)
+	return unix.Major(rdev), unix.Minor(rdev), nil
+}
+
+// getWindowSize reads the window size with the TIOCGWINSZ ioctl.
+func getWindowSize(fd uintptr) (WindowSize, error) {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return WindowSize{}, err
+	}
+	return WindowSize{Columns: int(ws.Col), Rows: int(ws.Row), Width: int(ws.Xpixel), Height: int(ws.Ypixel)}, nil
+}
+
+// isDevice reports whether path is a character device with the given device number.
+func isDevice(path string, rdev uint64) bool {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return false
+	}
+	return st.Mode&unix.S_IFMT == unix.S_IFCHR && uint64(st.Rdev) == rdev
+}
+
+// read reads from the file descriptor, retrying when interrupted by a signal.
+func read(fd uintptr, p []byte) (int, error) {
+	for {
+		n, err := unix.Read(int(fd), p)
+		if err == unix.EINTR {
+			continue
+		}
+		return n, err
+	}
+}
+
+// write writes to the file descriptor, retrying when interrupted by a signal.
+func write(fd uintptr, p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := unix.Write(int(fd), p[written:])
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// wait uses poll(2) to wait for input on the file descriptor.
+func wait(fd uintptr, timeout time.Duration) (bool, error) {
+	ms := -1
+	if timeout >= 0 {
+		ms = int(timeout / time.Millisecond)
+	}
+
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, ms)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return n > 0, nil
+	}
+}
+
+// isForeground compares the foreground process group of the terminal, obtained with TIOCGPGRP,
+// with the process group of the current process.
+func isForeground(fd uintptr) (bool, error) {
+	pgrp, err := unix.IoctlGetInt(int(fd), unix.TIOCGPGRP)
+	if err != nil {
+		return false, err
+	}
+
+	own, err := unix.Getpgid(0)
+	if err != nil {
+		return false, err
+	}
+	return pgrp == own, nil
+}
+
+// resumeSignals returns SIGCONT, which is delivered when a stopped job is resumed with fg or bg.
+func resumeSignals() []os.Signal {
+	return []os.Signal{unix.SIGCONT}
+}
+
+// suspendSignals returns SIGTSTP, which is delivered when the user presses the suspend character.
+func suspendSignals() []os.Signal {
+	return []os.Signal{unix.SIGTSTP}
+}
+
+// terminationSignals returns the signals that terminate a process on Unix-like systems,
+// including SIGHUP, which is delivered when the terminal goes away.
+func terminationSignals() []os.Signal {
+	return []os.Signal{unix.SIGINT, unix.SIGTERM, unix.SIGHUP}
+}
+
+// raise sends the signal to the current process with kill(2).
+func raise(sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return ErrNotSupported
+	}
+	return unix.Kill(os.Getpid(), s)
+}
+
+// suspend stops the current process with SIGSTOP, which cannot be caught or ignored.
+func suspend() error {
+	return unix.Kill(os.Getpid(), unix.SIGSTOP)
+}
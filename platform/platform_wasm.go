@@ -3,7 +3,15 @@
 
 package platform
 
-import "syscall/js"
+import (
+	"os"
+	"syscall"
+	"syscall/js"
+	"time"
+)
+
+// state is empty in WASM environments, which expose no terminal modes.
+type state struct{}
 
 // isTerminal determines if the file descriptor is a terminal in a WASM environment.
 // For WebAssembly, it checks for Node.js terminal properties.
@@ -38,3 +46,94 @@ func isTerminal(fd uintptr) bool {
 func isCygwin(fd uintptr) bool {
 	return false
 }
+
+// name is not supported in a WASM environment.
+func name(fd uintptr) (string, error) {
+	return "", ErrNotSupported
+}
+
+// deviceNumber is not supported in a WASM environment.
+func deviceNumber(fd uintptr) (uint32, uint32, error) {
+	return 0, 0, ErrNotSupported
+}
+
+// getAttributes is not supported in a WASM environment.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	return nil, ErrNotSupported
+}
+
+// getState is not supported in a WASM environment.
+func getState(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// setState is not supported in a WASM environment.
+func setState(fd uintptr, s *State) error {
+	return ErrNotSupported
+}
+
+// disableEcho is not supported in a WASM environment.
+func disableEcho(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// makeRaw is not supported in a WASM environment.
+func makeRaw(fd uintptr) (*State, error) {
+	return nil, ErrNotSupported
+}
+
+// getWindowSize is not supported in a WASM environment.
+func getWindowSize(fd uintptr) (WindowSize, error) {
+	return WindowSize{}, ErrNotSupported
+}
+
+// read reads from the file descriptor through the host file system bindings.
+func read(fd uintptr, p []byte) (int, error) {
+	return syscall.Read(int(fd), p)
+}
+
+// write writes to the file descriptor through the host file system bindings.
+func write(fd uintptr, p []byte) (int, error) {
+	return syscall.Write(int(fd), p)
+}
+
+// wait always reports input as available in a WASM environment.
+func wait(fd uintptr, timeout time.Duration) (bool, error) {
+	return true, nil
+}
+
+// isForeground reports whether the file descriptor is a terminal.
+// A WASM environment has no job control, so a terminal is always in the foreground.
+func isForeground(fd uintptr) (bool, error) {
+	return isTerminal(fd), nil
+}
+
+// isTostop is not supported in a WASM environment.
+func isTostop(fd uintptr) (bool, error) {
+	return false, ErrNotSupported
+}
+
+// resumeSignals returns no signals because there is no job control in a WASM environment.
+func resumeSignals() []os.Signal {
+	return nil
+}
+
+// suspendSignals returns no signals because there is no job control in a WASM environment.
+func suspendSignals() []os.Signal {
+	return nil
+}
+
+// terminationSignals returns no signals because WASM processes cannot receive them.
+func terminationSignals() []os.Signal {
+	return nil
+}
+
+// raise is not supported in a WASM environment.
+func raise(sig os.Signal) error {
+	return ErrNotSupported
+}
+
+// suspend is not supported because there is no job control in a WASM environment.
+func suspend() error {
+	return ErrNotSupported
+}
@@ -4,8 +4,10 @@
 package platform
 
 import (
+	"os"
 	"strings"
 	"syscall"
+	"time"
 	"unicode/utf16"
 	"unsafe"
 )
@@ -15,19 +17,46 @@ const (
 	fileTypeChar   = 2 // Character file type constant for GetFileType
 	fileNameInfo   = 2 // File name information class constant for GetFileInformationByHandleEx
 	objectNameInfo = 1 // Object name information class constant for NtQueryObject
+
+	enableProcessedInput   = 0x0001 // Console input mode flag for system handling of Ctrl-C
+	enableLineInput        = 0x0002 // Console input mode flag for line-buffered reads
+	enableEchoInput        = 0x0004 // Console input mode flag for echoing typed characters
+	enableVirtualTermInput = 0x0200 // Console input mode flag for receiving VT escape sequences
+
+	enableProcessedOutput    = 0x0001 // Console output mode flag for processing control characters
+	disableNewlineAutoReturn = 0x0008 // Console output mode flag that stops newline from returning the cursor
+
+	waitTimeout = 258 // WAIT_TIMEOUT return value of WaitForSingleObject
 )
 
 // Windows API function pointers and flags
 var (
-	kernel32                         = syscall.NewLazyDLL("kernel32.dll")
-	ntdll                            = syscall.NewLazyDLL("ntdll.dll")
-	procGetConsoleMode               = kernel32.NewProc("GetConsoleMode")
-	procGetFileInformationByHandleEx = kernel32.NewProc("GetFileInformationByHandleEx")
-	procGetFileType                  = kernel32.NewProc("GetFileType")
-	procNtQueryObject                = ntdll.NewProc("NtQueryObject")
-	hasGetFileInfoByHandleEx         = procGetFileInformationByHandleEx.Find() == nil
+	kernel32                          = syscall.NewLazyDLL("kernel32.dll")
+	ntdll                             = syscall.NewLazyDLL("ntdll.dll")
+	procGetConsoleMode                = kernel32.NewProc("GetConsoleMode")
+	procGetConsoleScreenBufferInfo    = kernel32.NewProc("GetConsoleScreenBufferInfo")
+	procGetNumberOfConsoleInputEvents = kernel32.NewProc("GetNumberOfConsoleInputEvents")
+	procSetConsoleMode                = kernel32.NewProc("SetConsoleMode")
+	procGetFileInformationByHandleEx  = kernel32.NewProc("GetFileInformationByHandleEx")
+	procGetFileType                   = kernel32.NewProc("GetFileType")
+	procNtQueryObject                 = ntdll.NewProc("NtQueryObject")
+	hasGetFileInfoByHandleEx          = procGetFileInformationByHandleEx.Find() == nil
 )
 
+// consoleScreenBufferInfo mirrors the CONSOLE_SCREEN_BUFFER_INFO structure.
+type consoleScreenBufferInfo struct {
+	size              [2]int16 // Size of the screen buffer in cells
+	cursorPosition    [2]int16 // Position of the cursor
+	attributes        uint16   // Attributes of written characters
+	window            [4]int16 // Left, top, right and bottom of the visible window
+	maximumWindowSize [2]int16 // Largest possible window size
+}
+
+// state holds the console mode of a Windows console handle.
+type state struct {
+	mode uint32
+}
+
 // isTerminal checks if the file descriptor is a Windows console.
 // It uses the GetConsoleMode function, which is available on all Windows versions.
 func isTerminal(fd uintptr) bool {
@@ -50,32 +79,33 @@ func isCygwin(fd uintptr) bool {
 		return false
 	}
 
-	// Get pipe name using the appropriate method based on Windows version.
-	var pipeName string
-	var err error
-
-	if hasGetFileInfoByHandleEx {
-		// Use GetFileInformationByHandleEx on newer Windows versions.
-		var buf [2 + syscall.MAX_PATH]uint16
-		r, _, _ := syscall.Syscall6(procGetFileInformationByHandleEx.Addr(),
-			4, fd, fileNameInfo, uintptr(unsafe.Pointer(&buf)), uintptr(len(buf)*2), 0, 0)
-		if r == 0 {
-			return false
-		}
-		l := *(*uint32)(unsafe.Pointer(&buf))
-		pipeName = string(utf16.Decode(buf[2 : 2+l/2]))
-	} else {
-		// Fallback to NtQueryObject on older Windows versions (XP, Server 2003).
-		pipeName, err = getFileNameByHandle(fd)
-		if err != nil {
-			return false
-		}
+	pipeName, err := getPipeName(fd)
+	if err != nil {
+		return false
 	}
 
 	// Check if the pipe name matches the Cygwin/MSYS2 naming pattern.
 	return isCygwinPipeName(pipeName)
 }
 
+// getPipeName returns the name of the pipe behind the handle.
+// It uses GetFileInformationByHandleEx on newer Windows versions and falls back to NtQueryObject.
+func getPipeName(fd uintptr) (string, error) {
+	if !hasGetFileInfoByHandleEx {
+		// Fallback to NtQueryObject on older Windows versions (XP, Server 2003).
+		return getFileNameByHandle(fd)
+	}
+
+	var buf [2 + syscall.MAX_PATH]uint16
+	r, _, e := syscall.Syscall6(procGetFileInformationByHandleEx.Addr(),
+		4, fd, fileNameInfo, uintptr(unsafe.Pointer(&buf)), uintptr(len(buf)*2), 0, 0)
+	if r == 0 {
+		return "", e
+	}
+	l := *(*uint32)(unsafe.Pointer(&buf))
+	return string(utf16.Decode(buf[2 : 2+l/2])), nil
+}
+
 // isCygwinPipeName checks if a pipe name matches the Cygwin/MSYS2 naming pattern.
 // Cygwin/MSYS2 PTY has a name like: \{cygwin,msys}-XXXXXXXXXXXXXXXX-ptyN-{from,to}-master
 func isCygwinPipeName(name string) bool {
@@ -120,3 +150,187 @@ func getFileNameByHandle(fd uintptr) (string, error) {
 	}
 	return string(utf16.Decode(buf[4 : 4+buf[0]/2])), nil
 }
+
+// name returns the console device name of the handle, CONIN$ for input and CONOUT$ for output.
+// For Cygwin/MSYS2 terminals it returns the name of the underlying pipe.
+func name(fd uintptr) (string, error) {
+	if isCygwin(fd) {
+		return getPipeName(fd)
+	}
+
+	if _, err := getState(fd); err != nil {
+		return "", err
+	}
+
+	if isConsoleInput(fd) {
+		return "CONIN$", nil
+	}
+	return "CONOUT$", nil
+}
+
+// isConsoleInput reports whether the handle is a console input handle.
+// GetNumberOfConsoleInputEvents only succeeds on input handles.
+func isConsoleInput(fd uintptr) bool {
+	var events uint32
+	r, _, _ := syscall.Syscall(procGetNumberOfConsoleInputEvents.Addr(), 2, fd, uintptr(unsafe.Pointer(&events)), 0)
+	return r != 0
+}
+
+// getAttributes decodes the console mode of the handle.
+// Input handles report the input flags and output handles report the output flags.
+// The control characters are fixed by the console: Ctrl-C interrupts, Ctrl-Z ends input and Backspace erases.
+func getAttributes(fd uintptr) (*Attributes, error) {
+	s, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := &Attributes{Interrupt: 0x03, EOF: 0x1a, Erase: 0x08}
+	if isConsoleInput(fd) {
+		attrs.Echo = s.mode&enableEchoInput != 0
+		attrs.Canonical = s.mode&enableLineInput != 0
+		attrs.Signals = s.mode&enableProcessedInput != 0
+	} else {
+		attrs.OutputProcessing = s.mode&enableProcessedOutput != 0
+		attrs.MapNewline = attrs.OutputProcessing && s.mode&disableNewlineAutoReturn == 0
+	}
+	return attrs, nil
+}
+
+// deviceNumber is not supported on Windows, which has no device numbers.
+func deviceNumber(fd uintptr) (uint32, uint32, error) {
+	return 0, 0, ErrNotSupported
+}
+
+// getState reads the console mode of the handle using GetConsoleMode.
+func getState(fd uintptr) (*State, error) {
+	var mode uint32
+	r, _, e := syscall.Syscall(procGetConsoleMode.Addr(), 2, fd, uintptr(unsafe.Pointer(&mode)), 0)
+	if r == 0 {
+		return nil, e
+	}
+	return &State{state{mode: mode}}, nil
+}
+
+// setState restores the console mode of the handle using SetConsoleMode.
+func setState(fd uintptr, s *State) error {
+	return setConsoleMode(fd, s.mode)
+}
+
+// disableEcho clears the echo, line input and processed input flags of the console.
+// Without processed input, Ctrl-C is read as a character instead of being delivered as an interrupt.
+func disableEcho(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := old.mode &^ (enableEchoInput | enableLineInput | enableProcessedInput)
+	if err := setConsoleMode(fd, mode); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// makeRaw disables echo, line input and processed input, and enables virtual terminal input
+// so that special keys are delivered as VT escape sequences.
+func makeRaw(fd uintptr) (*State, error) {
+	old, err := getState(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := old.mode&^(enableEchoInput|enableLineInput|enableProcessedInput) | enableVirtualTermInput
+	if err := setConsoleMode(fd, mode); err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// setConsoleMode calls SetConsoleMode and converts a failure into an error.
+func setConsoleMode(fd uintptr, mode uint32) error {
+	r, _, e := syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(mode), 0)
+	if r == 0 {
+		return e
+	}
+	return nil
+}
+
+// getWindowSize returns the size of the visible window of a console screen buffer.
+// The console does not report pixel sizes.
+func getWindowSize(fd uintptr) (WindowSize, error) {
+	var info consoleScreenBufferInfo
+	r, _, e := syscall.Syscall(procGetConsoleScreenBufferInfo.Addr(), 2, fd, uintptr(unsafe.Pointer(&info)), 0)
+	if r == 0 {
+		return WindowSize{}, e
+	}
+	return WindowSize{
+		Columns: int(info.window[2]-info.window[0]) + 1,
+		Rows:    int(info.window[3]-info.window[1]) + 1,
+	}, nil
+}
+
+// read reads from the handle using ReadFile.
+func read(fd uintptr, p []byte) (int, error) {
+	return syscall.Read(syscall.Handle(fd), p)
+}
+
+// write writes to the handle using WriteFile.
+func write(fd uintptr, p []byte) (int, error) {
+	return syscall.Write(syscall.Handle(fd), p)
+}
+
+// wait waits for the handle to become signaled using WaitForSingleObject.
+// Console input handles are signaled while unread input events are pending.
+func wait(fd uintptr, timeout time.Duration) (bool, error) {
+	ms := uint32(syscall.INFINITE)
+	if timeout >= 0 {
+		ms = uint32(timeout / time.Millisecond)
+	}
+
+	r, err := syscall.WaitForSingleObject(syscall.Handle(fd), ms)
+	if err != nil {
+		return false, err
+	}
+	return r != waitTimeout, nil
+}
+
+// isForeground reports whether the handle is a console.
+// Windows consoles have no job control, so a console is always in the foreground.
+func isForeground(fd uintptr) (bool, error) {
+	if _, err := getState(fd); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isTostop always returns false on Windows because consoles have no job control.
+func isTostop(fd uintptr) (bool, error) {
+	return false, nil
+}
+
+// resumeSignals returns no signals because Windows processes cannot be stopped and continued.
+func resumeSignals() []os.Signal {
+	return nil
+}
+
+// suspendSignals returns no signals because Windows processes cannot be stopped and continued.
+func suspendSignals() []os.Signal {
+	return nil
+}
+
+// terminationSignals returns the signals Go delivers for console control events on Windows.
+func terminationSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
+
+// raise is not supported because Windows provides no way to deliver a signal to the current process.
+// The caller decides whether to exit instead.
+func raise(sig os.Signal) error {
+	return ErrNotSupported
+}
+
+// suspend is not supported because Windows processes cannot be stopped and continued.
+func suspend() error {
+	return ErrNotSupported
+}
@@ -7,22 +7,24 @@ import (
 	"github.com/droqsic/probe/platform"
 )
 
-// Cache store the result of IsTerminal and IsCygwinTerminal calls for each file descriptor.
+// Cache store the result of IsTerminal, IsCygwinTerminal and TerminalName calls for each file descriptor.
 // It is used to avoid calling the underlying platform functions multiple times for the same file descriptor.
 var (
 	cache = struct {
-		terminal map[uintptr]bool // Maps file descriptors to terminal status
-		cygwin   map[uintptr]bool // Maps file descriptors to Cygwin status
-		mutex    sync.RWMutex     // Protects concurrent access to the maps
+		terminal map[uintptr]bool   // Maps file descriptors to terminal status
+		cygwin   map[uintptr]bool   // Maps file descriptors to Cygwin status
+		name     map[uintptr]string // Maps file descriptors to terminal device paths
+		mutex    sync.RWMutex       // Protects concurrent access to the maps
 	}{
 		terminal: make(map[uintptr]bool),
 		cygwin:   make(map[uintptr]bool),
+		name:     make(map[uintptr]string),
 	}
 )
 
 // getCache retrieves a cached result for a file descriptor.
 // It returns the cached value and a boolean indicating if the value was found in the cache.
-func getCache(m map[uintptr]bool, fd uintptr) (bool, bool) {
+func getCache[T any](m map[uintptr]T, fd uintptr) (T, bool) {
 	cache.mutex.RLock()
 	defer cache.mutex.RUnlock()
 	result, ok := m[fd]
@@ -30,7 +32,7 @@ func getCache(m map[uintptr]bool, fd uintptr) (bool, bool) {
 }
 
 // setCache stores a result for a file descriptor in the cache.
-func setCache(m map[uintptr]bool, fd uintptr, result bool) {
+func setCache[T any](m map[uintptr]T, fd uintptr, result T) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 	m[fd] = result
@@ -80,4 +82,5 @@ func ClearCache() {
 	defer cache.mutex.Unlock()
 	cache.terminal = make(map[uintptr]bool)
 	cache.cygwin = make(map[uintptr]bool)
+	cache.name = make(map[uintptr]string)
 }
@@ -0,0 +1,176 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// DefaultQueryTimeout is how long a terminal query waits for the reply when the context has no deadline.
+// It leaves room for the round trip over a slow SSH connection.
+const DefaultQueryTimeout = time.Second
+
+// da1Request asks for the Primary Device Attributes, which every terminal answers.
+// It is sent after each query as a sentinel: terminals reply in order, so once its reply arrives
+// any reply to the query has arrived too.
+const da1Request = "\x1b[c"
+
+// sequence is a control sequence found in a terminal reply.
+type sequence struct {
+	intro byte   // Byte after the escape: '[' for CSI, ']' for OSC, 'P' for DCS, '_' for APC
+	body  string // Bytes between the introducer and the final byte or string terminator
+	final byte   // Final byte of a CSI sequence, or zero for string sequences
+}
+
+// isDA1 reports whether the sequence is a Primary Device Attributes reply, "CSI ? ... c".
+func (s sequence) isDA1() bool {
+	return s.intro == '[' && s.final == 'c' && len(s.body) > 0 && s.body[0] == '?'
+}
+
+// hasPrefix reports whether the body of a sequence starts with prefix.
+func (s sequence) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(s.body, prefix)
+}
+
+// params returns the numeric parameters of a CSI sequence, skipping a leading private marker such as '?' or '>'.
+// Missing or non-numeric parameters are returned as -1.
+func (s sequence) params() []int {
+	body := strings.TrimLeft(s.body, "?><=")
+	if body == "" {
+		return nil
+	}
+
+	fields := strings.Split(body, ";")
+	params := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			n = -1
+		}
+		params[i] = n
+	}
+	return params
+}
+
+// query writes request followed by the DA1 sentinel and reads the replies until the sentinel's reply arrives.
+// It returns the control sequences received before the sentinel's reply, and that reply itself.
+// An empty request only sends the sentinel. The terminal is in raw mode while the replies are read,
+// so that they are neither echoed nor held back until a newline.
+// If ctx has no deadline, DefaultQueryTimeout applies; ErrNoReply is returned when it expires.
+func query(ctx context.Context, fd uintptr, request string) ([]sequence, sequence, error) {
+	return exchange(ctx, fd, request+da1Request)
+}
+
+// exchange writes data, which must end with the DA1 sentinel in some form, and reads replies as query does.
+// It lets callers wrap the whole request, sentinel included, for a terminal multiplexer to pass through,
+// so that both are answered by the same terminal and in order.
+func exchange(ctx context.Context, fd uintptr, data string) ([]sequence, sequence, error) {
+	if !IsTerminal(fd) {
+		return nil, sequence{}, &NotTerminalError{Fd: fd}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultQueryTimeout)
+		defer cancel()
+	}
+
+	state, err := platform.MakeRaw(fd)
+	if err != nil && err != platform.ErrNotSupported {
+		return nil, sequence{}, err
+	}
+	if err == nil {
+		defer platform.SetState(fd, state)
+	}
+
+	if _, err := platform.Write(fd, []byte(data)); err != nil {
+		return nil, sequence{}, err
+	}
+
+	var buf []byte
+	var replies []sequence
+	chunk := make([]byte, 256)
+	for {
+		if err := waitInput(ctx, fd); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, sequence{}, ErrNoReply
+			}
+			return nil, sequence{}, err
+		}
+
+		n, err := platform.Read(fd, chunk)
+		if err != nil {
+			return nil, sequence{}, err
+		}
+		buf = append(buf, chunk[:n]...)
+
+		seqs, used := parseSequences(buf)
+		buf = buf[used:]
+		for i, seq := range seqs {
+			if seq.isDA1() {
+				return append(replies, seqs[:i]...), seq, nil
+			}
+		}
+		replies = append(replies, seqs...)
+	}
+}
+
+// parseSequences extracts the complete control sequences from a terminal reply and returns
+// them with the number of bytes consumed. Bytes outside sequences, such as keys typed
+// while the reply was in flight, are skipped; a trailing incomplete sequence is left unconsumed.
+func parseSequences(b []byte) ([]sequence, int) {
+	var seqs []sequence
+	i := 0
+	for i < len(b) {
+		if b[i] != 0x1b {
+			i++
+			continue
+		}
+		if i+1 >= len(b) {
+			break
+		}
+
+		seq, n := parseSequence(b[i:])
+		if n == 0 {
+			break
+		}
+		if seq.intro != 0 {
+			seqs = append(seqs, seq)
+		}
+		i += n
+	}
+	return seqs, i
+}
+
+// parseSequence parses the control sequence at the start of b, which begins with an escape.
+// It returns zero bytes consumed when the sequence is incomplete, and a zero sequence for
+// escapes that do not start a CSI or string sequence.
+func parseSequence(b []byte) (sequence, int) {
+	switch intro := b[1]; intro {
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return sequence{intro: intro, body: string(b[2:i]), final: b[i]}, i + 1
+			}
+		}
+		return sequence{}, 0
+	case ']', 'P', '_', '^':
+		// String sequences end with BEL or with the string terminator ESC \.
+		for i := 2; i < len(b); i++ {
+			switch {
+			case b[i] == 0x07:
+				return sequence{intro: intro, body: string(b[2:i])}, i + 1
+			case b[i] == 0x1b && i+1 < len(b) && b[i+1] == '\\':
+				return sequence{intro: intro, body: string(b[2:i])}, i + 2
+			case b[i] == 0x1b && i+1 == len(b):
+				return sequence{}, 0
+			}
+		}
+		return sequence{}, 0
+	}
+	return sequence{}, 2
+}
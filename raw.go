@@ -0,0 +1,32 @@
+package probe
+
+import (
+	"github.com/droqsic/probe/platform"
+)
+
+// State is a saved terminal state returned by MakeRaw.
+// Pass it to Restore to return the terminal to the mode it was in before.
+type State struct {
+	state *platform.State
+}
+
+// MakeRaw puts the terminal into raw mode, where input is delivered byte by byte
+// without echo, line editing or signal generation.
+// It returns the previous state, which should be restored with Restore before the program exits.
+// It returns a *NotTerminalError if the file descriptor is not a terminal.
+func MakeRaw(fd uintptr) (*State, error) {
+	if !IsTerminal(fd) {
+		return nil, &NotTerminalError{Fd: fd}
+	}
+
+	old, err := platform.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return &State{state: old}, nil
+}
+
+// Restore returns the terminal to a state previously returned by MakeRaw.
+func Restore(fd uintptr, s *State) error {
+	return platform.SetState(fd, s.state)
+}
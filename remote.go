@@ -0,0 +1,153 @@
+package probe
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultProcRoot is where procfs is mounted on Linux.
+const defaultProcRoot = "/proc"
+
+// maxAncestors bounds the walk up the process tree, in case procfs reports a cycle.
+const maxAncestors = 64
+
+// Names of the remote development environments reported by RemoteSession.
+const (
+	RemoteIDECloudShell = "cloud-shell" // Google Cloud Shell
+	RemoteIDECodespaces = "codespaces"  // GitHub Codespaces
+	RemoteIDEGitpod     = "gitpod"      // Gitpod workspaces
+	RemoteIDEVSCode     = "vscode"      // A VS Code remote connection over SSH or to a dev container
+)
+
+// Remote describes how the user reaches the machine the process runs on, when it is not directly.
+type Remote struct {
+	SSH           bool   `json:"ssh"`            // Whether the session came in over SSH
+	Mosh          bool   `json:"mosh"`           // Whether the terminal is served by mosh, which hides SSH's latency
+	IDE           string `json:"ide"`            // One of the RemoteIDE constants, or empty
+	ClientAddress string `json:"client_address"` // Address of the SSH client, or empty if unknown
+	ClientPort    int    `json:"client_port"`    // Port of the SSH client, or zero if unknown
+	ServerAddress string `json:"server_address"` // Address the SSH client connected to, or empty if unknown
+	ServerPort    int    `json:"server_port"`    // Port the SSH client connected to, or zero if unknown
+	SSHTTY        string `json:"ssh_tty"`        // Terminal device allocated by sshd, from SSH_TTY, or empty
+	OnSSHTTY      bool   `json:"on_ssh_tty"`     // Whether the file descriptor is that terminal, rather than one started later
+}
+
+// RemoteOption configures RemoteSession.
+type RemoteOption func(*remoteOptions)
+
+// remoteOptions holds the settings applied by RemoteOption values.
+type remoteOptions struct {
+	procRoot string // Directory where procfs is mounted
+}
+
+// WithProcRoot reads process information from root instead of /proc.
+// It is mainly useful for testing against a fake procfs tree.
+func WithProcRoot(root string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.procRoot = root
+	}
+}
+
+// RemoteSession reports whether the process runs in a remote session, where every terminal query
+// costs a network round trip and animations should be throttled. SSH is detected from SSH_CONNECTION,
+// SSH_CLIENT and SSH_TTY, which also give the client's address and port. On Linux the ancestors of the
+// process are checked as well, which finds mosh and SSH sessions whose variables were removed, for example by sudo.
+// Remote development environments are detected from the variables they set.
+// OnSSHTTY reports whether the terminal behind fd, as named by TerminalName, is the one sshd allocated;
+// it is false inside tmux or screen, whose terminals outlive the connection.
+// The result is not cached because it depends on the environment.
+func RemoteSession(fd uintptr, opts ...RemoteOption) (Remote, bool) {
+	o := remoteOptions{procRoot: defaultProcRoot}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var r Remote
+	if fields := strings.Fields(os.Getenv("SSH_CONNECTION")); len(fields) == 4 {
+		r.SSH = true
+		r.ClientAddress, r.ClientPort = fields[0], atoiOrZero(fields[1])
+		r.ServerAddress, r.ServerPort = fields[2], atoiOrZero(fields[3])
+	} else if fields := strings.Fields(os.Getenv("SSH_CLIENT")); len(fields) == 3 {
+		r.SSH = true
+		r.ClientAddress, r.ClientPort, r.ServerPort = fields[0], atoiOrZero(fields[1]), atoiOrZero(fields[2])
+	}
+	if r.SSHTTY = os.Getenv("SSH_TTY"); r.SSHTTY != "" {
+		r.SSH = true
+		if name, err := TerminalName(fd); err == nil {
+			r.OnSSHTTY = sameFile(name, r.SSHTTY)
+		}
+	}
+
+	for _, name := range ancestors(o.procRoot) {
+		switch name {
+		case "mosh-server":
+			r.Mosh = true
+		case "sshd", "sshd-session":
+			r.SSH = true
+		}
+	}
+
+	switch {
+	case os.Getenv("CODESPACES") == "true":
+		r.IDE = RemoteIDECodespaces
+	case os.Getenv("GITPOD_WORKSPACE_ID") != "":
+		r.IDE = RemoteIDEGitpod
+	case os.Getenv("CLOUD_SHELL") == "true":
+		r.IDE = RemoteIDECloudShell
+	case os.Getenv("REMOTE_CONTAINERS") == "true" || os.Getenv("VSCODE_IPC_HOOK_CLI") != "" && r.SSH:
+		r.IDE = RemoteIDEVSCode
+	}
+
+	return r, r.SSH || r.Mosh || r.IDE != ""
+}
+
+// ancestors returns the command names of the ancestors of the process, parent first, read from procfs.
+// It returns what it found before the first error, which is nothing on systems without procfs.
+func ancestors(root string) []string {
+	var names []string
+	pid := os.Getppid()
+	for range maxAncestors {
+		if pid <= 1 {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "stat"))
+		if err != nil {
+			break
+		}
+
+		// The stat line is "pid (comm) state ppid ...", where comm can itself contain spaces and parentheses.
+		stat := string(data)
+		open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+		if open < 0 || end < open {
+			break
+		}
+		names = append(names, stat[open+1:end])
+		fields := strings.Fields(stat[end+1:])
+		if len(fields) < 2 {
+			break
+		}
+		pid = atoiOrZero(fields[1])
+	}
+	return names
+}
+
+// sameFile reports whether two paths name the same file, comparing the files themselves when both exist.
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// atoiOrZero parses a decimal number, returning zero if s is not one.
+func atoiOrZero(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
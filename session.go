@@ -0,0 +1,328 @@
+package probe
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// Session records the terminal mode changes made through it and reverts them together,
+// so that a program never leaves the user's shell in raw mode, on the alternate screen or with a hidden cursor.
+//
+// Changes are reverted in the reverse order they were made when Close is called,
+// when a goroutine guarded by Recover or Go panics, and when the process receives a termination signal,
+// which is raised again afterwards, or passed to the program with ForwardSignals. When the process is
+// suspended with SIGTSTP the changes are reverted before it stops and made again once it is continued.
+// Signals that are ignored, as under nohup, are left ignored.
+//
+// A typical program creates a session at startup:
+//
+//	session := probe.NewSession(os.Stdin.Fd())
+//	defer session.Close()
+//	defer session.Recover()
+//
+// Mode changes requested on a file descriptor that is not a terminal write nothing and are not recorded.
+// A Session is safe for concurrent use.
+type Session struct {
+	fd uintptr // File descriptor whose modes are changed
+
+	mutex     sync.Mutex // Protects the fields below and serializes changes with signal handling
+	changes   []change   // Changes in effect, oldest first
+	suspended bool       // Whether the changes are reverted because the process is stopped
+	closed    bool       // Whether Close has been called
+
+	forward   chan<- os.Signal // Receives termination signals after the changes are reverted, or nil to raise them
+	terminate chan os.Signal   // Receives termination signals
+	suspend   chan os.Signal   // Receives suspend signals
+	resume    chan os.Signal   // Receives resume signals
+	done      chan struct{}    // Closed by Close to stop the signal handler
+}
+
+// change is a recorded mode change that can be undone and made again.
+type change struct {
+	apply  func() error // Makes the change
+	revert func() error // Undoes the change
+}
+
+// SessionOption configures NewSession.
+type SessionOption func(*sessionOptions)
+
+// sessionOptions holds the settings applied by SessionOption values.
+type sessionOptions struct {
+	forward chan<- os.Signal // Channel that termination signals are forwarded to, or nil
+}
+
+// ForwardSignals sends termination signals to ch once the session has reverted its changes and closed,
+// instead of raising them again. Use it in programs that handle SIGINT, SIGTERM or SIGHUP themselves,
+// in place of calling signal.Notify for them, so that each signal is received once and the program
+// decides whether to exit. As with signal.Notify, ch should be buffered; a signal is dropped if ch is not ready.
+func ForwardSignals(ch chan<- os.Signal) SessionOption {
+	return func(o *sessionOptions) {
+		o.forward = ch
+	}
+}
+
+// NewSession returns a Session that changes the modes of the terminal on fd.
+// The session starts handling signals immediately; call Close to stop.
+func NewSession(fd uintptr, opts ...SessionOption) *Session {
+	var o sessionOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &Session{
+		fd:        fd,
+		forward:   o.forward,
+		terminate: make(chan os.Signal, 1),
+		suspend:   make(chan os.Signal, 1),
+		resume:    make(chan os.Signal, 1),
+		done:      make(chan struct{}),
+	}
+
+	if sigs := caught(platform.TerminationSignals()); len(sigs) > 0 {
+		signal.Notify(s.terminate, sigs...)
+	}
+	if sigs := caught(platform.SuspendSignals()); len(sigs) > 0 {
+		signal.Notify(s.suspend, sigs...)
+	}
+	if sigs := platform.ResumeSignals(); len(sigs) > 0 {
+		signal.Notify(s.resume, sigs...)
+	}
+
+	go s.handleSignals()
+	return s
+}
+
+// MakeRaw puts the terminal into raw mode until the session is closed.
+// It returns a *NotTerminalError if the file descriptor is not a terminal.
+func (s *Session) MakeRaw() error {
+	if !IsTerminal(s.fd) {
+		return &NotTerminalError{Fd: s.fd}
+	}
+
+	// The state is captured each time the change is made, so that changes the shell
+	// makes while the process is suspended are kept when it is continued.
+	var old *platform.State
+	return s.record(
+		func() (err error) {
+			old, err = platform.MakeRaw(s.fd)
+			return err
+		},
+		func() error { return platform.SetState(s.fd, old) },
+	)
+}
+
+// EnterAltScreen switches to the alternate screen until the session is closed.
+// It shares the reference count of the package-level EnterAltScreen and ExitAltScreen.
+func (s *Session) EnterAltScreen() error {
+	if !IsTerminal(s.fd) {
+		return nil
+	}
+	return s.record(
+		func() error { return enterAltScreen(s.fd, false) },
+		func() error { return ExitAltScreen(s.fd) },
+	)
+}
+
+// SetTitle sets a title of the terminal until the session is closed, as the package-level SetTitle does.
+func (s *Session) SetTitle(kind TitleKind, title string) error {
+	if !IsTerminal(s.fd) || !supportsTitle() {
+		return nil
+	}
+	apply, revert := titleChange(s.fd, kind, title)
+	return s.record(apply, revert)
+}
+
+// HideCursor hides the text cursor until the session is closed.
+func (s *Session) HideCursor() error {
+	return s.recordModes(false, modeCursor)
+}
+
+// EnableBracketedPaste turns on bracketed paste mode until the session is closed.
+func (s *Session) EnableBracketedPaste() error {
+	return s.recordModes(true, modeBracketedPaste)
+}
+
+// EnableFocusReporting turns on focus reporting until the session is closed.
+func (s *Session) EnableFocusReporting() error {
+	return s.recordModes(true, modeFocus)
+}
+
+// EnableMouse turns on mouse tracking with the given report encoding until the session is closed.
+// It returns ErrUnknownMouseMode if tracking or encoding is not one of the constants.
+func (s *Session) EnableMouse(tracking MouseTracking, encoding MouseEncoding) error {
+	modes, err := mouseModeList(tracking, encoding)
+	if err != nil {
+		return err
+	}
+	return s.recordModes(true, modes...)
+}
+
+// PushKittyKeyboard pushes kitty keyboard protocol flags until the session is closed.
+func (s *Session) PushKittyKeyboard(flags KittyKeyboardFlags) error {
+	if !IsTerminal(s.fd) {
+		return nil
+	}
+	return s.record(
+		func() error { return writeKitty(s.fd, '>', int(flags)) },
+		func() error { return writeKitty(s.fd, '<', 1) },
+	)
+}
+
+// Close reverts every change made through the session, newest first, and stops handling signals.
+// It returns the first error encountered, after attempting every revert. Calling it again does nothing.
+func (s *Session) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	signal.Stop(s.terminate)
+	signal.Stop(s.suspend)
+	signal.Stop(s.resume)
+	close(s.done)
+
+	var err error
+	if !s.suspended {
+		err = s.revert()
+	}
+	s.changes = nil
+	return err
+}
+
+// Recover reverts the session's changes if the calling goroutine is panicking, then continues the panic,
+// so that the stack trace is printed to a usable terminal. It must be called directly by a defer statement.
+func (s *Session) Recover() {
+	if r := recover(); r != nil {
+		s.Close()
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine guarded by Recover.
+// A panic in any goroutine ends the program without running the deferred calls of the others,
+// so goroutines started while the terminal is in a modified mode should use it.
+func (s *Session) Go(fn func()) {
+	go func() {
+		defer s.Recover()
+		fn()
+	}()
+}
+
+// recordModes records a change that sets private modes, or resets them if set is false.
+func (s *Session) recordModes(set bool, modes ...int) error {
+	if !IsTerminal(s.fd) {
+		return nil
+	}
+	return s.record(
+		func() error { return setPrivateMode(s.fd, set, modes...) },
+		func() error { return setPrivateMode(s.fd, !set, modes...) },
+	)
+}
+
+// record makes a change and remembers it so that it can be reverted.
+func (s *Session) record(apply, revert func() error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if s.suspended {
+		// The change is made along with the others once the process is continued.
+		s.changes = append(s.changes, change{apply: apply, revert: revert})
+		return nil
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	s.changes = append(s.changes, change{apply: apply, revert: revert})
+	return nil
+}
+
+// revert undoes the recorded changes, newest first, and returns the first error.
+// The caller must hold the mutex.
+func (s *Session) revert() error {
+	var first error
+	for i := len(s.changes) - 1; i >= 0; i-- {
+		if err := s.changes[i].revert(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// reapply makes the recorded changes again, oldest first, and returns the first error.
+// The caller must hold the mutex.
+func (s *Session) reapply() error {
+	var first error
+	for _, c := range s.changes {
+		if err := c.apply(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// handleSignals reverts the changes when a termination or suspend signal arrives, until the session is closed.
+func (s *Session) handleSignals() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case sig := <-s.terminate:
+			// Close stops the notification, so raising the signal again takes its default action.
+			s.Close()
+			if s.forward == nil {
+				reraise(sig)
+				return
+			}
+			select {
+			case s.forward <- sig:
+			default:
+			}
+			return
+		case <-s.suspend:
+			s.stop()
+		}
+	}
+}
+
+// stop reverts the changes, stops the process and makes the changes again once it is continued.
+// The mutex is not held while the process is stopped, so that Close still works if it is never continued.
+func (s *Session) stop() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	_ = s.revert()
+	s.suspended = true
+	s.mutex.Unlock()
+
+	// A caught suspend signal no longer stops the process, so it is stopped explicitly.
+	select {
+	case <-s.resume:
+	default:
+	}
+	if err := platform.Suspend(); err == nil {
+		select {
+		case <-s.resume:
+		case <-s.done:
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.suspended = false
+	_ = s.reapply()
+}
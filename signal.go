@@ -0,0 +1,79 @@
+package probe
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// restoreSignals is whether the package-level functions catch termination signals, as set by RestoreOnSignal.
+var restoreSignals atomic.Bool
+
+// RestoreOnSignal sets whether the changes made by the package-level functions, such as EnableBracketedPaste,
+// HideCursor, EnterAltScreen, SetTitle and ReadPassword, are undone when the process receives SIGINT, SIGTERM
+// or SIGHUP while they are in effect. The signal is then raised again so that its default action still takes place.
+//
+// It is off by default, because a program that handles these signals itself would receive each of them twice
+// and keep running with its modes turned off. Programs that handle signals should use a Session with
+// ForwardSignals instead. Signals that are ignored, as under nohup, are never caught.
+func RestoreOnSignal(enable bool) {
+	restoreSignals.Store(enable)
+}
+
+// restoreOnSignal runs restore if the process receives a termination signal before stop is called,
+// provided RestoreOnSignal is enabled. After restore returns, the signal is raised again.
+// The returned stop function is safe to call multiple times.
+func restoreOnSignal(restore func()) (stop func()) {
+	if !restoreSignals.Load() {
+		return func() {}
+	}
+	sigs := caught(platform.TerminationSignals())
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		select {
+		case sig := <-ch:
+			restore()
+			signal.Stop(ch)
+			reraise(sig)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// caught returns the signals that are not ignored. Catching an ignored signal would stop it from being
+// ignored, so that a program started with nohup, for example, would be ended by SIGHUP.
+func caught(sigs []os.Signal) []os.Signal {
+	var result []os.Signal
+	for _, sig := range sigs {
+		if !signal.Ignored(sig) {
+			result = append(result, sig)
+		}
+	}
+	return result
+}
+
+// reraise raises a caught signal again once nothing catches it any more, so that its default action takes place.
+// Where a signal cannot be raised, as on Windows, the process exits with status 1 instead.
+func reraise(sig os.Signal) {
+	if err := platform.Raise(sig); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,19 @@
+package probe
+
+import (
+	"github.com/droqsic/probe/platform"
+)
+
+// WindowSize is the size of a terminal window in character cells and, where the terminal reports it, in pixels.
+type WindowSize = platform.WindowSize
+
+// Size returns the current size of the terminal window.
+// It returns a *NotTerminalError if the file descriptor is not a terminal.
+// On Windows the file descriptor must be an output handle, such as os.Stdout.
+// The result is not cached because the window can be resized at any time.
+func Size(fd uintptr) (WindowSize, error) {
+	if !IsTerminal(fd) {
+		return WindowSize{}, &NotTerminalError{Fd: fd}
+	}
+	return platform.GetWindowSize(fd)
+}
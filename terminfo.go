@@ -0,0 +1,139 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Indexes of the string capabilities read from terminfo, in the order defined by term(5).
+const (
+	capSmcup = 28 // Enter the mode used by full-screen programs, usually the alternate screen
+	capRmcup = 40 // Leave that mode
+)
+
+// Magic numbers of the compiled terminfo formats.
+const (
+	terminfoMagic   = 0o432  // Legacy format with 16-bit numbers
+	terminfoMagic32 = 0o1036 // Extended format with 32-bit numbers
+)
+
+// Errors returned when loading terminfo entries.
+var (
+	errNoTerminfo      = errors.New("probe: no terminfo entry")
+	errInvalidTerminfo = errors.New("probe: invalid terminfo entry")
+)
+
+// terminfo holds the string capabilities of a compiled terminfo entry.
+// Missing capabilities are empty.
+type terminfo struct {
+	strings []string
+}
+
+// str returns the string capability at index i, or an empty string if it is missing.
+func (t *terminfo) str(i int) string {
+	if i >= len(t.strings) {
+		return ""
+	}
+	return t.strings[i]
+}
+
+// loadTerminfo reads the compiled terminfo entry for the terminal type in $TERM.
+// It searches $TERMINFO, ~/.terminfo, $TERMINFO_DIRS and the usual system directories,
+// using both the letter and the hexadecimal directory layouts.
+func loadTerminfo() (*terminfo, error) {
+	term := os.Getenv("TERM")
+	if term == "" || strings.ContainsAny(term, "/\\") {
+		return nil, errNoTerminfo
+	}
+
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("TERMINFO_DIRS")) {
+		if dir == "" {
+			dir = "/usr/share/terminfo"
+		}
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo")
+
+	for _, dir := range dirs {
+		for _, sub := range []string{term[:1], fmt.Sprintf("%x", term[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err == nil {
+				return parseTerminfo(data)
+			}
+		}
+	}
+	return nil, errNoTerminfo
+}
+
+// parseTerminfo decodes the string capabilities of a compiled terminfo entry, as described in term(5).
+func parseTerminfo(data []byte) (*terminfo, error) {
+	if len(data) < 12 {
+		return nil, errInvalidTerminfo
+	}
+
+	header := make([]int, 6)
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(data[i*2:])))
+	}
+	numberSize := 2
+	switch header[0] {
+	case terminfoMagic:
+	case terminfoMagic32:
+		numberSize = 4
+	default:
+		return nil, errInvalidTerminfo
+	}
+	namesSize, boolCount, numCount, strCount, tableSize := header[1], header[2], header[3], header[4], header[5]
+	if namesSize < 0 || boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return nil, errInvalidTerminfo
+	}
+
+	// The numbers start on an even offset, so a padding byte follows an odd-sized names and booleans section.
+	offset := 12 + namesSize + boolCount
+	offset += offset % 2
+	offset += numCount * numberSize
+	table := offset + strCount*2
+	if table+tableSize > len(data) {
+		return nil, errInvalidTerminfo
+	}
+
+	t := &terminfo{strings: make([]string, strCount)}
+	for i := range strCount {
+		pos := int(int16(binary.LittleEndian.Uint16(data[offset+i*2:])))
+		if pos < 0 || pos >= tableSize {
+			continue
+		}
+		value := data[table+pos : table+tableSize]
+		if end := strings.IndexByte(string(value), 0); end >= 0 {
+			value = value[:end]
+		}
+		t.strings[i] = stripPadding(string(value))
+	}
+	return t, nil
+}
+
+// stripPadding removes terminfo delay specifications such as "$<5>", which are meant for hardware terminals.
+func stripPadding(s string) string {
+	for {
+		start := strings.Index(s, "$<")
+		if start < 0 {
+			return s
+		}
+		end := strings.IndexByte(s[start:], '>')
+		if end < 0 {
+			return s
+		}
+		s = s[:start] + s[start+end+1:]
+	}
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// writeTerminfo writes a compiled terminfo entry with the given string capabilities to dir, in the letter layout.
+func writeTerminfo(t *testing.T, dir, name string, caps map[int]string) {
+	t.Helper()
+
+	count := 0
+	for i := range caps {
+		count = max(count, i+1)
+	}
+	var table []byte
+	offsets := make([]int16, count)
+	for i := range offsets {
+		offsets[i] = -1
+		if value, ok := caps[i]; ok {
+			offsets[i] = int16(len(table))
+			table = append(append(table, value...), 0)
+		}
+	}
+
+	names := name + "\x00"
+	var buf bytes.Buffer
+	for _, v := range []int16{0o432, int16(len(names)), 0, 0, int16(count), int16(len(table))} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString(names)
+	if buf.Len()%2 != 0 {
+		buf.WriteByte(0)
+	}
+	binary.Write(&buf, binary.LittleEndian, offsets)
+	buf.Write(table)
+
+	path := filepath.Join(dir, name[:1], name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create terminfo directory: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write terminfo entry: %v", err)
+	}
+}
+
+// TestAltScreen tests the sequences used to enter and exit the alternate screen, and their reference counting.
+func TestAltScreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		term     string
+		caps     map[int]string
+		expected string
+	}{
+		{"terminfo", "probe-test", map[int]string{28: "\x1b[?1049h$<5>\x1b[22t", 40: "\x1b[?1049l\x1b[23t"}, "\x1b[?1049h\x1b[22t\x1b[?1049l\x1b[23t"},
+		{"terminfo-without-smcup", "probe-dumb", map[int]string{5: "\x1b[H\x1b[2J"}, ""},
+		{"fallback", "probe-missing", nil, "\x1b[?1049h\x1b[?1049l"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.caps != nil {
+				writeTerminfo(t, dir, tt.term, tt.caps)
+			}
+			t.Setenv("TERM", tt.term)
+			t.Setenv("TERMINFO", dir)
+
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, nil)
+			fd := slave.Fd()
+
+			for range 2 {
+				if err := probe.EnterAltScreen(fd); err != nil {
+					t.Fatalf("EnterAltScreen failed: %v", err)
+				}
+			}
+			for range 3 {
+				if err := probe.ExitAltScreen(fd); err != nil {
+					t.Fatalf("ExitAltScreen failed: %v", err)
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if got := string(stop()); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestAltScreenNonTerminal tests that nothing is written to a pipe.
+func TestAltScreenNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := probe.EnterAltScreen(w.Fd()); err != nil {
+		t.Errorf("EnterAltScreen failed: %v", err)
+	}
+	if err := probe.ExitAltScreen(w.Fd()); err != nil {
+		t.Errorf("ExitAltScreen failed: %v", err)
+	}
+
+	w.Close()
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); n != 0 {
+		t.Errorf("Expected nothing to be written to the pipe, got %q", buf[:n])
+	}
+}
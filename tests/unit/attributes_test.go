@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestAttributesNonTerminal tests that Attributes rejects non-terminal file descriptors.
+func TestAttributesNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	_, err = probe.Attributes(r.Fd())
+
+	var notTerminal *probe.NotTerminalError
+	if !errors.As(err, &notTerminal) {
+		t.Errorf("Expected *NotTerminalError, got %v", err)
+	}
+}
+
+// TestAttributesStandardStreams tests that Attributes succeeds for every terminal among the standard streams.
+func TestAttributesStandardStreams(t *testing.T) {
+	for _, fd := range []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()} {
+		if !probe.IsTerminal(fd) {
+			continue
+		}
+
+		attrs, err := probe.Attributes(fd)
+		if err != nil {
+			t.Errorf("Attributes failed for terminal fd %d: %v", fd, err)
+			continue
+		}
+		t.Logf("fd %d: %s", fd, attrs)
+	}
+}
+
+// TestControlCharString tests the caret notation of control characters.
+func TestControlCharString(t *testing.T) {
+	tests := []struct {
+		char     probe.ControlChar
+		expected string
+	}{
+		{0x03, "^C"},
+		{0x04, "^D"},
+		{0x7f, "^?"},
+		{0x00, "<undef>"},
+		{'q', "q"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.char.String(); got != tt.expected {
+			t.Errorf("Expected %q for %#x, got %q", tt.expected, byte(tt.char), got)
+		}
+	}
+}
+
+// TestTerminalAttributesFormat tests the String and JSON output of TerminalAttributes.
+func TestTerminalAttributesFormat(t *testing.T) {
+	attrs := probe.TerminalAttributes{
+		Echo:             true,
+		Canonical:        true,
+		Signals:          true,
+		OutputProcessing: true,
+		MapNewline:       true,
+		InputSpeed:       9600,
+		OutputSpeed:      9600,
+		Interrupt:        0x03,
+		EOF:              0x04,
+		Erase:            0x7f,
+	}
+
+	expected := "speed 9600 baud; echo icanon isig -ixon opost onlcr; intr = ^C; eof = ^D; erase = ^?"
+	if got := attrs.String(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	data, err := json.Marshal(&attrs)
+	if err != nil {
+		t.Fatalf("Failed to marshal attributes: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal attributes: %v", err)
+	}
+	if decoded["interrupt"] != "^C" || decoded["echo"] != true || decoded["flow_control"] != false {
+		t.Errorf("Unexpected JSON output: %s", data)
+	}
+}
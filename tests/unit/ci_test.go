@@ -0,0 +1,98 @@
+package unit
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// ciPrefixes lists the prefixes of the environment variables DetectCI reads, so that tests running
+// in a real CI build do not see its variables.
+var ciPrefixes = []string{
+	"CI", "GITHUB_", "GITLAB_", "BUILDKITE", "CIRCLE", "TF_BUILD", "SYSTEM_", "BUILD_", "TEAMCITY_",
+	"TRAVIS", "DRONE", "JENKINS_", "GIT_", "CHANGE_", "BRANCH_NAME",
+}
+
+// setCI makes the environment look like the given CI service for the rest of the test.
+// Every variable DetectCI reads is unset before vars are set; all are restored when the test ends.
+func setCI(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		for _, prefix := range ciPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+				break
+			}
+		}
+	}
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+}
+
+// TestDetectCI tests the detection of each service and the metadata read from its variables.
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected probe.CI
+	}{
+		{"github pull request", map[string]string{
+			"CI": "true", "GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/pull/42/merge", "GITHUB_HEAD_REF": "feature",
+			"GITHUB_REF_NAME": "42/merge", "GITHUB_SHA": "abc123", "GITHUB_REPOSITORY": "droqsic/probe",
+			"GITHUB_RUN_ID": "7", "GITHUB_SERVER_URL": "https://github.com",
+		}, probe.CI{
+			Provider: probe.CIGitHubActions, ANSIColor: true, PullRequest: "42", Branch: "feature", Commit: "abc123",
+			Repository: "droqsic/probe", BuildID: "7", BuildURL: "https://github.com/droqsic/probe/actions/runs/7",
+		}},
+		{"github push", map[string]string{"GITHUB_ACTIONS": "true", "GITHUB_REF": "refs/heads/main", "GITHUB_REF_NAME": "main"},
+			probe.CI{Provider: probe.CIGitHubActions, ANSIColor: true, Branch: "main"}},
+		{"gitlab", map[string]string{"GITLAB_CI": "true", "CI_MERGE_REQUEST_IID": "5", "CI_COMMIT_REF_NAME": "fix", "CI_JOB_URL": "https://gitlab.com/x/-/jobs/1"},
+			probe.CI{Provider: probe.CIGitLab, ANSIColor: true, PullRequest: "5", Branch: "fix", BuildURL: "https://gitlab.com/x/-/jobs/1"}},
+		{"buildkite", map[string]string{"BUILDKITE": "true", "BUILDKITE_PULL_REQUEST": "false", "BUILDKITE_BRANCH": "main"},
+			probe.CI{Provider: probe.CIBuildkite, ANSIColor: true, Branch: "main"}},
+		{"circleci", map[string]string{"CIRCLECI": "true", "CIRCLE_PULL_REQUEST": "https://github.com/o/r/pull/9", "CIRCLE_PROJECT_USERNAME": "o", "CIRCLE_PROJECT_REPONAME": "r"},
+			probe.CI{Provider: probe.CICircleCI, ANSIColor: true, PullRequest: "9", Repository: "o/r"}},
+		{"azure", map[string]string{"TF_BUILD": "True", "BUILD_BUILDID": "12", "SYSTEM_COLLECTIONURI": "https://dev.azure.com/org/", "SYSTEM_TEAMPROJECT": "proj", "SYSTEM_PULLREQUEST_SOURCEBRANCH": "refs/heads/topic"},
+			probe.CI{Provider: probe.CIAzurePipelines, ANSIColor: true, Branch: "topic", BuildID: "12", BuildURL: "https://dev.azure.com/org/proj/_build/results?buildId=12"}},
+		{"teamcity", map[string]string{"TEAMCITY_VERSION": "2024.1", "BUILD_NUMBER": "3"},
+			probe.CI{Provider: probe.CITeamCity, ANSIColor: true, BuildID: "3"}},
+		{"travis", map[string]string{"TRAVIS": "true", "TRAVIS_PULL_REQUEST": "false", "TRAVIS_BRANCH": "main", "TRAVIS_REPO_SLUG": "o/r"},
+			probe.CI{Provider: probe.CITravis, ANSIColor: true, Branch: "main", Repository: "o/r"}},
+		{"drone", map[string]string{"DRONE": "true", "DRONE_PULL_REQUEST": "4", "DRONE_SOURCE_BRANCH": "dev"},
+			probe.CI{Provider: probe.CIDrone, ANSIColor: true, PullRequest: "4", Branch: "dev"}},
+		{"jenkins", map[string]string{"JENKINS_URL": "https://ci.example.com/", "BUILD_ID": "8", "BUILD_NUMBER": "8", "CHANGE_ID": "11", "BRANCH_NAME": "PR-11"},
+			probe.CI{Provider: probe.CIJenkins, PullRequest: "11", Branch: "PR-11", BuildID: "8"}},
+		{"generic", map[string]string{"CI": "1"}, probe.CI{Provider: probe.CIGeneric}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCI(t, tt.env)
+			got, ok := probe.DetectCI()
+			if !ok {
+				t.Fatalf("Expected a CI build to be detected")
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestDetectCINone tests that no build is detected without CI variables or with CI explicitly off.
+func TestDetectCINone(t *testing.T) {
+	for _, value := range []string{"", "false", "0"} {
+		setCI(t, nil)
+		if value != "" {
+			t.Setenv("CI", value)
+		}
+		if ci, ok := probe.DetectCI(); ok {
+			t.Errorf("Expected no CI build with CI=%q, got %+v", value, ci)
+		}
+	}
+}
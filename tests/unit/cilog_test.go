@@ -0,0 +1,96 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// writeCILog writes a group containing a line and an annotation in the syntax of provider and returns the output.
+func writeCILog(t *testing.T, provider probe.CIProvider, annotation probe.Annotation) string {
+	t.Helper()
+	var buf bytes.Buffer
+	log := probe.NewCIWriter(&buf, provider)
+	err := log.Group("Run tests", func() error {
+		log.Write([]byte("ok\n"))
+		return log.Annotate(annotation)
+	})
+	if err != nil {
+		t.Fatalf("Writing the log failed: %v", err)
+	}
+	return buf.String()
+}
+
+// TestCIWriter tests groups and annotations in the syntax of each service.
+func TestCIWriter(t *testing.T) {
+	located := probe.Annotation{Level: probe.AnnotationError, Message: "bad value\nhere", Title: "Vet", File: "main.go", Line: 12, Column: 5}
+	tests := []struct {
+		name       string
+		provider   probe.CIProvider
+		annotation probe.Annotation
+		expected   string
+	}{
+		{"github", probe.CIGitHubActions, located,
+			"::group::Run tests\nok\n::error file=main.go,line=12,col=5,title=Vet::bad value%0Ahere\n::endgroup::\n"},
+		{"github bare", probe.CIGitHubActions, probe.Annotation{Level: probe.AnnotationWarning, Message: "50% done"},
+			"::group::Run tests\nok\n::warning::50%25 done\n::endgroup::\n"},
+		{"azure", probe.CIAzurePipelines, located,
+			"##[group]Run tests\nok\n##vso[task.logissue type=error;sourcepath=main.go;linenumber=12;columnnumber=5]bad value%0Ahere\n##[endgroup]\n"},
+		{"teamcity", probe.CITeamCity, probe.Annotation{Level: probe.AnnotationWarning, Message: "it's [slow]"},
+			"##teamcity[blockOpened name='Run tests']\nok\n##teamcity[message text='it|'s |[slow|]' status='WARNING']\n##teamcity[blockClosed name='Run tests']\n"},
+		{"buildkite", probe.CIBuildkite, probe.Annotation{Message: "failed"},
+			"--- Run tests\nok\n^^^ +++\nerror: failed\n"},
+		{"travis", probe.CITravis, probe.Annotation{Level: probe.AnnotationNotice, Message: "cached"},
+			"travis_fold:start:run_tests_1\r\x1b[0KRun tests\nok\nnotice: cached\ntravis_fold:end:run_tests_1\r\x1b[0K"},
+		{"plain", "", located,
+			"Run tests\nok\nerror: main.go:12:5: Vet: bad value\nhere\n"},
+		{"jenkins", probe.CIJenkins, probe.Annotation{Level: probe.AnnotationWarning, Message: "flaky", File: "x_test.go"},
+			"Run tests\nok\nwarning: x_test.go: flaky\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeCILog(t, tt.provider, tt.annotation); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestCIWriterGitLab tests GitLab section markers, which carry a timestamp and nest.
+func TestCIWriterGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	log := probe.NewCIWriter(&buf, probe.CIGitLab)
+	log.StartGroup("Build")
+	log.StartGroup("Compile: step 1")
+	log.EndGroup()
+	log.EndGroup()
+	if err := log.EndGroup(); err != nil {
+		t.Errorf("Ending a group that is not open failed: %v", err)
+	}
+
+	pattern := regexp.MustCompile(`^` +
+		`\x1b\[0Ksection_start:\d+:build_1\[collapsed=true\]\r\x1b\[0KBuild\n` +
+		`\x1b\[0Ksection_start:\d+:compile_step_1_2\[collapsed=true\]\r\x1b\[0KCompile: step 1\n` +
+		`\x1b\[0Ksection_end:\d+:compile_step_1_2\r\x1b\[0K\n` +
+		`\x1b\[0Ksection_end:\d+:build_1\r\x1b\[0K\n$`)
+	if got := buf.String(); !pattern.MatchString(got) {
+		t.Errorf("Unexpected sections %q", got)
+	}
+}
+
+// TestCIWriterGroupError tests that a group is ended when its function fails and that the error is returned.
+func TestCIWriterGroupError(t *testing.T) {
+	var buf bytes.Buffer
+	log := probe.NewCIWriter(&buf, probe.CIGitHubActions)
+	failure := errors.New("failure")
+	if err := log.Group("Lint", func() error { return failure }); err != failure {
+		t.Errorf("Expected the function's error, got %v", err)
+	}
+	if got := buf.String(); got != "::group::Lint\n::endgroup::\n" {
+		t.Errorf("Unexpected output %q", got)
+	}
+}
@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSetClipboard tests the OSC 52 sequences written for different emulators and multiplexers.
+func TestSetClipboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		selection probe.Selection
+		expected  string
+	}{
+		{"xterm", map[string]string{"TERM": "xterm-256color"}, probe.SelectionClipboard, "\x1b]52;c;aGVsbG8=\x1b\\"},
+		{"primary", map[string]string{"TERM": "xterm-kitty"}, probe.SelectionPrimary, "\x1b]52;p;aGVsbG8=\x1b\\"},
+		{"tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux-0/default,1,0"}, probe.SelectionClipboard, "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\x1b\x1b\\\x1b\\"},
+		{"screen", map[string]string{"TERM": "screen", "STY": "1.pts-0.host"}, probe.SelectionClipboard, "\x1bP\x1b]52;c;aGVsbG8=\a\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, nil)
+
+			if err := probe.SetClipboard(slave.Fd(), tt.selection, []byte("hello")); err != nil {
+				t.Fatalf("SetClipboard failed: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if got := string(stop()); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSetClipboardScreenChunks tests that long sequences are split into several DCS strings inside GNU screen.
+func TestSetClipboardScreenChunks(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "screen", "STY": "1.pts-0.host"})
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	if err := probe.SetClipboard(slave.Fd(), probe.SelectionClipboard, bytes.Repeat([]byte("x"), 300)); err != nil {
+		t.Fatalf("SetClipboard failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	got := string(stop())
+	chunks := strings.Split(strings.TrimSuffix(got, "\x1b\\"), "\x1b\\")
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several DCS strings, got %q", got)
+	}
+	var inner strings.Builder
+	for _, chunk := range chunks {
+		if !strings.HasPrefix(chunk, "\x1bP") || len(chunk) > 80 {
+			t.Fatalf("Unexpected DCS string %q", chunk)
+		}
+		inner.WriteString(chunk[2:])
+	}
+	if !strings.HasPrefix(inner.String(), "\x1b]52;c;eHh4") || !strings.HasSuffix(inner.String(), "\a") {
+		t.Errorf("Unexpected sequence %q", inner.String())
+	}
+}
+
+// TestSetClipboardErrors tests the errors for non-terminals, unsupported emulators and oversized data.
+func TestSetClipboardErrors(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var notTerminal *probe.NotTerminalError
+	if err := probe.SetClipboard(w.Fd(), probe.SelectionClipboard, []byte("x")); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+
+	_, slave := openPTY(t)
+	setEmulator(t, map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "7600"})
+	if err := probe.SetClipboard(slave.Fd(), probe.SelectionClipboard, []byte("x")); !errors.Is(err, probe.ErrClipboardUnavailable) {
+		t.Errorf("Expected ErrClipboardUnavailable on VTE, got %v", err)
+	}
+
+	setEmulator(t, map[string]string{"TERM": "xterm-256color"})
+	if err := probe.SetClipboard(slave.Fd(), probe.SelectionClipboard, make([]byte, 100000)); !errors.Is(err, probe.ErrClipboardTooLarge) {
+		t.Errorf("Expected ErrClipboardTooLarge, got %v", err)
+	}
+}
+
+// TestGetClipboard tests reading the clipboard from terminals that answer, refuse and pass through tmux.
+func TestGetClipboard(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		replies  map[string]string
+		expected string
+		err      error
+	}{
+		{"answered", map[string]string{"TERM": "xterm-256color"}, map[string]string{"\x1b]52;c;?\x1b\\": "\x1b]52;c;aGVsbG8=\a", "\x1b[c": "\x1b[?62c"}, "hello", nil},
+		{"denied", map[string]string{"TERM": "xterm-256color"}, map[string]string{"\x1b[c": "\x1b[?62c"}, "", probe.ErrClipboardUnavailable},
+		{"tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux-0/default,1,0"}, map[string]string{"\x1bPtmux;\x1b\x1b]52;c;?\x1b\x1b\\\x1b\x1b[c\x1b\\": "\x1b]52;c;aGk=\x1b\\\x1b[?62c"}, "hi", nil},
+		{"unsupported", map[string]string{"TERM": "linux"}, nil, "", probe.ErrClipboardUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, tt.replies)
+			defer stop()
+
+			data, err := probe.GetClipboard(context.Background(), slave.Fd())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, data)
+			}
+		})
+	}
+}
+
+// TestGetClipboardNoReply tests that a terminal that never answers yields both ErrClipboardUnavailable and ErrNoReply.
+func TestGetClipboardNoReply(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "xterm-256color"})
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := probe.GetClipboard(ctx, slave.Fd())
+	if !errors.Is(err, probe.ErrClipboardUnavailable) || !errors.Is(err, probe.ErrNoReply) {
+		t.Errorf("Expected ErrClipboardUnavailable and ErrNoReply, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestClassifyDeviceMajor tests classification by well-known Linux major numbers.
+// An empty sysfs root is used so that only the major number table is consulted.
+func TestClassifyDeviceMajor(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name     string
+		major    uint32
+		minor    uint32
+		expected probe.Kind
+	}{
+		{"/dev/pts/3", 136, 3, probe.KindPTY},
+		{"/dev/ptmx", 5, 2, probe.KindPTY},
+		{"/dev/ttyp0", 3, 0, probe.KindPTY},
+		{"/dev/tty1", 4, 1, probe.KindVirtualConsole},
+		{"/dev/ttyS0", 4, 64, probe.KindSerial},
+		{"/dev/ttyAMA0", 204, 64, probe.KindSerial},
+		{"/dev/ttyUSB0", 188, 0, probe.KindUSBSerial},
+		{"/dev/ttyACM0", 166, 0, probe.KindUSBSerial},
+		{"", 5, 1, probe.KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected.String()+tt.name, func(t *testing.T) {
+			kind := probe.ClassifyDevice(tt.name, tt.major, tt.minor, probe.WithSysfsRoot(root))
+			if kind != tt.expected {
+				t.Errorf("Expected %v for %s (%d:%d), got %v", tt.expected, tt.name, tt.major, tt.minor, kind)
+			}
+		})
+	}
+}
+
+// TestClassifyDeviceName tests the naming conventions used when device numbers are not conclusive.
+func TestClassifyDeviceName(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name     string
+		expected probe.Kind
+	}{
+		{"/dev/ttys003", probe.KindPTY},
+		{"/dev/ttyv0", probe.KindVirtualConsole},
+		{"/dev/cuau0", probe.KindSerial},
+		{"/dev/tty.usbserial-1410", probe.KindUSBSerial},
+		{"/dev/ttyU0", probe.KindUSBSerial},
+		{"/dev/console", probe.KindUnknown},
+		{"", probe.KindUnknown},
+	}
+
+	for _, tt := range tests {
+		if kind := probe.ClassifyDevice(tt.name, 0, 0, probe.WithSysfsRoot(root)); kind != tt.expected {
+			t.Errorf("Expected %v for %q, got %v", tt.expected, tt.name, kind)
+		}
+	}
+}
+
+// TestClassifyDeviceSysfs tests classification through a fake sysfs tree.
+// Devices with dynamically allocated major numbers are resolved via /sys/dev/char and /sys/class/tty.
+func TestClassifyDeviceSysfs(t *testing.T) {
+	root := t.TempDir()
+
+	// makeTTY creates a tty class entry whose device lives under devicePath and belongs to subsystem.
+	makeTTY := func(name, devnum, devicePath, subsystem string) {
+		t.Helper()
+
+		class := filepath.Join(root, "class", "tty", name)
+		if err := os.MkdirAll(class, 0755); err != nil {
+			t.Fatalf("Failed to create class directory: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, "dev", "char"), 0755); err != nil {
+			t.Fatalf("Failed to create dev directory: %v", err)
+		}
+		if err := os.Symlink(class, filepath.Join(root, "dev", "char", devnum)); err != nil {
+			t.Skipf("Symlinks are not supported: %v", err)
+		}
+		if devicePath == "" {
+			return
+		}
+
+		device := filepath.Join(root, "devices", devicePath)
+		bus := filepath.Join(root, "bus", subsystem)
+		for _, dir := range []string{device, bus} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+		}
+		if err := os.Symlink(bus, filepath.Join(device, "subsystem")); err != nil {
+			t.Fatalf("Failed to create subsystem link: %v", err)
+		}
+		if err := os.Symlink(device, filepath.Join(class, "device")); err != nil {
+			t.Fatalf("Failed to create device link: %v", err)
+		}
+	}
+
+	makeTTY("ttyGS0", "239:0", "platform/gadget", "platform")
+	makeTTY("ttyXR0", "240:0", "pci0000:00/usb1/1-2/1-2:1.0", "usb")
+	makeTTY("tty7", "241:7", "", "")
+
+	tests := []struct {
+		major    uint32
+		minor    uint32
+		expected probe.Kind
+	}{
+		{239, 0, probe.KindSerial},
+		{240, 0, probe.KindUSBSerial},
+		{241, 7, probe.KindVirtualConsole},
+		{242, 0, probe.KindUnknown},
+	}
+
+	for _, tt := range tests {
+		if kind := probe.ClassifyDevice("", tt.major, tt.minor, probe.WithSysfsRoot(root)); kind != tt.expected {
+			t.Errorf("Expected %v for %d:%d, got %v", tt.expected, tt.major, tt.minor, kind)
+		}
+	}
+}
+
+// TestDeviceKindNonTerminal tests that non-terminal file descriptors are reported as unknown.
+func TestDeviceKindNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if kind := probe.DeviceKind(r.Fd()); kind != probe.KindUnknown {
+		t.Errorf("Expected pipe to be %v, got %v", probe.KindUnknown, kind)
+	}
+}
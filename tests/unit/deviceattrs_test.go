@@ -0,0 +1,142 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestPrimaryDeviceAttributes tests decoding DA1 replies of VT100-family and later terminals.
+func TestPrimaryDeviceAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		class    int
+		level    int
+		features []probe.Feature
+	}{
+		{"xterm", "\x1b[?64;1;2;6;9;15;16;17;18;21;22;28c", 64, 4, []probe.Feature{1, 2, 6, 9, 15, 16, 17, 18, 21, 22, 28}},
+		{"sixel", "\x1b[?62;4;22c", 62, 2, []probe.Feature{probe.FeatureSixel, probe.FeatureANSIColor}},
+		{"vt100", "\x1b[?1;2c", 1, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{"\x1b[c": tt.reply})
+			defer stop()
+
+			got, err := probe.PrimaryDeviceAttributes(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("PrimaryDeviceAttributes failed: %v", err)
+			}
+			if got.Class != tt.class || got.Level != tt.level || !slices.Equal(got.Features, tt.features) {
+				t.Errorf("Expected class %d, level %d and features %v, got %+v", tt.class, tt.level, tt.features, got)
+			}
+		})
+	}
+}
+
+// TestPrimaryAttributesHas tests looking up advertised features and their names.
+func TestPrimaryAttributesHas(t *testing.T) {
+	attrs := probe.PrimaryAttributes{Class: 62, Level: 2, Features: []probe.Feature{probe.FeatureSixel, probe.FeatureRectangularEditing}}
+	if !attrs.Has(probe.FeatureSixel) || !attrs.Has(probe.FeatureRectangularEditing) || attrs.Has(probe.FeatureANSIColor) {
+		t.Errorf("Unexpected features reported for %+v", attrs)
+	}
+	if got := probe.FeatureRectangularEditing.String(); got != "rectangular-editing" {
+		t.Errorf("Expected %q, got %q", "rectangular-editing", got)
+	}
+	if got := probe.Feature(99).String(); got != "unknown" {
+		t.Errorf("Expected %q, got %q", "unknown", got)
+	}
+}
+
+// TestSecondaryDeviceAttributes tests decoding DA2 replies and the terminal type catalog.
+func TestSecondaryDeviceAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		expected probe.SecondaryAttributes
+		terminal string
+		ok       bool
+	}{
+		{"xterm", "\x1b[>41;390;0c\x1b[?64c", probe.SecondaryAttributes{TerminalType: 41, Firmware: 390}, "VT420", true},
+		{"tmux", "\x1b[>84;0;0c\x1b[?62c", probe.SecondaryAttributes{TerminalType: 84}, "tmux", true},
+		{"short", "\x1b[>1;4000c\x1b[?62c", probe.SecondaryAttributes{TerminalType: 1, Firmware: 4000}, "VT220", true},
+		{"unknown id", "\x1b[>99;10;0c\x1b[?62c", probe.SecondaryAttributes{TerminalType: 99, Firmware: 10}, "", true},
+		{"unanswered", "\x1b[?1;2c", probe.SecondaryAttributes{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{"\x1b[>c\x1b[c": tt.reply})
+			defer stop()
+
+			got, ok, err := probe.SecondaryDeviceAttributes(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("SecondaryDeviceAttributes failed: %v", err)
+			}
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.expected, tt.ok, got, ok)
+			}
+			if name := got.TypeName(); ok && name != tt.terminal {
+				t.Errorf("Expected terminal %q, got %q", tt.terminal, name)
+			}
+		})
+	}
+}
+
+// TestTertiaryDeviceAttributes tests decoding the DA3 unit id and terminals that do not answer it.
+func TestTertiaryDeviceAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		unit  string
+		ok    bool
+	}{
+		{"answered", "\x1bP!|466f6f54\x1b\\\x1b[?62c", "466F6F54", true},
+		{"unanswered", "\x1b[?62c", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{"\x1b[=c\x1b[c": tt.reply})
+			defer stop()
+
+			got, ok, err := probe.TertiaryDeviceAttributes(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("TertiaryDeviceAttributes failed: %v", err)
+			}
+			if ok != tt.ok || got.UnitID != tt.unit {
+				t.Errorf("Expected %q (%v), got %q (%v)", tt.unit, tt.ok, got.UnitID, ok)
+			}
+		})
+	}
+}
+
+// TestDeviceAttributesNonTerminal tests that every request is refused on a non-terminal file descriptor.
+func TestDeviceAttributesNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var notTerminal *probe.NotTerminalError
+	if _, err := probe.PrimaryDeviceAttributes(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError from DA1, got %v", err)
+	}
+	if _, _, err := probe.SecondaryDeviceAttributes(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError from DA2, got %v", err)
+	}
+	if _, _, err := probe.TertiaryDeviceAttributes(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError from DA3, got %v", err)
+	}
+}
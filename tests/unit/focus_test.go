@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe/input"
+)
+
+// TestDecoderFocus tests decoding of focus reports, including one split across reads.
+func TestDecoderFocus(t *testing.T) {
+	d := input.NewDecoder()
+
+	var events []input.Event
+	for _, chunk := range []string{"\x1b[I", "\x1b", "[O"} {
+		events = append(events, d.Decode([]byte(chunk))...)
+	}
+
+	expected := []input.Event{input.FocusEvent{Focused: true}, input.FocusEvent{Focused: false}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %v, got %v", expected, events)
+	}
+}
+
+// TestWatchFocus tests that focus reports from a terminal are delivered and that reporting is turned on and off.
+func TestWatchFocus(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := input.WatchFocus(ctx, slave.Fd())
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := master.Write([]byte("x\x1b[O\x1b[I")); err != nil {
+		t.Fatalf("Failed to write focus reports: %v", err)
+	}
+
+	for _, expected := range []bool{false, true} {
+		select {
+		case got := <-ch:
+			if got != expected {
+				t.Errorf("Expected focus %v, got %v", expected, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for focus %v", expected)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	expected := "\x1b[?1004h\x1b[?1004l"
+	if got := string(stop()); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSupportsSynchronizedOutput tests the DECRQM replies that mean mode 2026 is and is not supported.
+func TestSupportsSynchronizedOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		expected bool
+	}{
+		{"reset", "\x1b[?2026;2$y\x1b[?62c", true},
+		{"set", "\x1b[?2026;1$y\x1b[?62c", true},
+		{"not recognized", "\x1b[?2026;0$y\x1b[?62c", false},
+		{"permanently reset", "\x1b[?2026;4$y\x1b[?62c", false},
+		{"no DECRQM", "\x1b[?1;2c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{"\x1b[?2026$p\x1b[c": tt.reply})
+			defer stop()
+
+			got, err := probe.SupportsSynchronizedOutput(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("SupportsSynchronizedOutput failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestFrameWriter tests that frames are wrapped in BSU and ESU only when the terminal supports it.
+func TestFrameWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		reply    string
+		expected string
+	}{
+		{"synchronized", "\x1b[?2026;2$y\x1b[?62c", "\x1b[?2026h\x1b[Hhello world\x1b[?2026l"},
+		{"unsupported", "\x1b[?62c", "\x1b[Hhello world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{"\x1b[?2026$p\x1b[c": tt.reply})
+
+			w := probe.NewFrameWriter(context.Background(), slave.Fd())
+			w.WriteString("\x1b[H")
+			w.Write([]byte("hello"))
+			w.WriteString(" world")
+			if w.Buffered() != 14 {
+				t.Errorf("Expected 14 bytes buffered, got %d", w.Buffered())
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush failed: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flushing an empty frame failed: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			got := string(stop())
+			expected := "\x1b[?2026$p\x1b[c" + tt.expected
+			if got != expected {
+				t.Errorf("Expected %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+// TestFrameWriterNonTerminal tests that frames written to a pipe are plain and the terminal is not queried.
+func TestFrameWriterNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	frames := probe.NewFrameWriter(context.Background(), w.Fd())
+	if frames.Synchronized() {
+		t.Errorf("Expected frames to a pipe not to be synchronized")
+	}
+	frames.WriteString("frame")
+	if err := frames.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); string(buf[:n]) != "frame" {
+		t.Errorf("Expected %q, got %q", "frame", buf[:n])
+	}
+
+	var notTerminal *probe.NotTerminalError
+	if _, err := probe.SupportsSynchronizedOutput(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// graphicsRequest is the batch of requests written by GraphicsSupport outside a multiplexer.
+const graphicsRequest = "\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\\x1b[?2;1;0S\x1b[16t\x1b[c"
+
+// TestGraphicsSupport tests the detection of each protocol from the replies of different terminals.
+func TestGraphicsSupport(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		reply    string
+		expected probe.Graphics
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, "\x1b_Gi=31;OK\x1b\\\x1b[6;20;10t\x1b[?62;22c", probe.Graphics{Kitty: true, CellWidth: 10, CellHeight: 20}},
+		{"sixel", map[string]string{"TERM": "xterm-256color"}, "\x1b[?2;0;1000;800S\x1b[6;16;8t\x1b[?63;1;4;22c", probe.Graphics{Sixel: true, SixelWidth: 1000, SixelHeight: 800, CellWidth: 8, CellHeight: 16}},
+		{"iterm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, "\x1b[?2;3;0;0S\x1b[?62;4c", probe.Graphics{Sixel: true, ITerm2: true}},
+		{"kitty error", map[string]string{"TERM": "xterm-256color"}, "\x1b_Gi=31;EINVAL:unsupported\x1b\\\x1b[?62c", probe.Graphics{}},
+		{"none", map[string]string{"TERM": "xterm-256color"}, "\x1b[?1;2c", probe.Graphics{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{graphicsRequest: tt.reply})
+			defer stop()
+
+			got, err := probe.GraphicsSupport(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("GraphicsSupport failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestGraphicsSupportWindowSize tests that the pixel size reported by the kernel takes precedence over CSI 16 t.
+func TestGraphicsSupportWindowSize(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "xterm-256color"})
+	master, slave := openPTY(t)
+	setWindowSize(t, master, 80, 24, 720, 432)
+	stop := fakeTerminal(t, master, map[string]string{graphicsRequest: "\x1b[6;20;10t\x1b[?62c"})
+	defer stop()
+
+	got, err := probe.GraphicsSupport(context.Background(), slave.Fd())
+	if err != nil {
+		t.Fatalf("GraphicsSupport failed: %v", err)
+	}
+	if got.CellWidth != 9 || got.CellHeight != 18 {
+		t.Errorf("Expected 9x18 cells, got %dx%d", got.CellWidth, got.CellHeight)
+	}
+}
+
+// TestGraphicsSupportTmux tests that the requests are passed through tmux to the outer terminal.
+func TestGraphicsSupportTmux(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux-0/default,1,0"})
+	master, slave := openPTY(t)
+	request := "\x1bPtmux;\x1b\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\x1b\\\x1b\x1b[?2;1;0S\x1b\x1b[16t\x1b\x1b[c\x1b\\"
+	stop := fakeTerminal(t, master, map[string]string{request: "\x1b_Gi=31;OK\x1b\\\x1b[?62c"})
+	defer stop()
+
+	got, err := probe.GraphicsSupport(context.Background(), slave.Fd())
+	if err != nil {
+		t.Fatalf("GraphicsSupport failed: %v", err)
+	}
+	if !got.Kitty {
+		t.Errorf("Expected kitty graphics through tmux, got %+v", got)
+	}
+}
+
+// TestGraphicsSupportErrors tests the errors for non-terminals and terminals that never answer.
+func TestGraphicsSupportErrors(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var notTerminal *probe.NotTerminalError
+	if _, err := probe.GraphicsSupport(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := probe.GraphicsSupport(ctx, slave.Fd()); !errors.Is(err, probe.ErrNoReply) {
+		t.Errorf("Expected ErrNoReply, got %v", err)
+	}
+}
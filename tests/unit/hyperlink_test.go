@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSupportsHyperlinks tests hyperlink detection for emulators, versions, multiplexers and overrides.
+func TestSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected bool
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, true},
+		{"iterm2-old", map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.0.15"}, false},
+		{"iterm2", map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM_PROGRAM_VERSION": "3.4.19"}, true},
+		{"vte-0.50.0", map[string]string{"VTE_VERSION": "5000"}, false},
+		{"vte", map[string]string{"VTE_VERSION": "7600"}, true},
+		{"vscode", map[string]string{"TERM_PROGRAM": "vscode", "TERM_PROGRAM_VERSION": "1.85.1"}, true},
+		{"xterm", map[string]string{"TERM": "xterm-256color", "XTERM_VERSION": "XTerm(390)"}, false},
+		{"unknown", map[string]string{"TERM": "xterm-256color"}, false},
+		{"tmux-old", map[string]string{"TERM": "tmux-256color", "TMUX": "x", "TERM_PROGRAM": "tmux", "TERM_PROGRAM_VERSION": "3.3a", "KITTY_WINDOW_ID": "1"}, false},
+		{"tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "x", "TERM_PROGRAM": "tmux", "TERM_PROGRAM_VERSION": "3.4", "KITTY_WINDOW_ID": "1"}, true},
+		{"screen", map[string]string{"TERM": "screen", "STY": "x", "KITTY_WINDOW_ID": "1"}, false},
+		{"forced-off", map[string]string{"TERM": "xterm-kitty", "FORCE_HYPERLINK": "0"}, false},
+		{"forced-on", map[string]string{"TERM": "xterm-256color", "FORCE_HYPERLINK": "1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			_, slave := openPTY(t)
+			if got := probe.SupportsHyperlinks(slave.Fd()); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSupportsHyperlinksNonTerminal tests that pipes only get links when forced.
+func TestSupportsHyperlinksNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	setEmulator(t, map[string]string{"TERM": "xterm-kitty"})
+	if probe.SupportsHyperlinks(w.Fd()) {
+		t.Errorf("Expected no hyperlinks on a pipe")
+	}
+	t.Setenv("FORCE_HYPERLINK", "1")
+	if !probe.SupportsHyperlinks(w.Fd()) {
+		t.Errorf("Expected FORCE_HYPERLINK to enable hyperlinks on a pipe")
+	}
+}
+
+// TestHyperlink tests link formatting and the plain text fallbacks.
+func TestHyperlink(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	fd := w.Fd()
+	url := "https://example.com/a"
+
+	setEmulator(t, map[string]string{"FORCE_HYPERLINK": "1"})
+	if got, expected := probe.Hyperlink(fd, url, "docs"), "\x1b]8;;https://example.com/a\x1b\\docs\x1b]8;;\x1b\\"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if got, expected := probe.Hyperlink(fd, url, "do\x1bcs", probe.WithLinkID("a:b;1")), "\x1b]8;id=ab1;https://example.com/a\x1b\\docs\x1b]8;;\x1b\\"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	setEmulator(t, map[string]string{"FORCE_HYPERLINK": "0"})
+	if got := probe.Hyperlink(fd, url, "docs"); got != "docs" {
+		t.Errorf("Expected plain text, got %q", got)
+	}
+	if got, expected := probe.Hyperlink(fd, url, "docs", probe.WithURLFallback()), "docs (https://example.com/a)"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if got := probe.Hyperlink(fd, url, url, probe.WithURLFallback()); got != url {
+		t.Errorf("Expected the URL once, got %q", got)
+	}
+}
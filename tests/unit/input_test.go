@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe/input"
+)
+
+// TestDecoderKeys tests decoding of text, control keys and legacy escape sequences.
+// Each input is decoded in one chunk and flushed, as if no more bytes follow.
+func TestDecoderKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"ascii", "ab", []string{"a", "b"}},
+		{"utf8", "é世", []string{"é", "世"}},
+		{"enter-tab-backspace", "\r\t\x7f", []string{"enter", "tab", "backspace"}},
+		{"ctrl-letters", "\x01\x03\x1a", []string{"ctrl+a", "ctrl+c", "ctrl+z"}},
+		{"ctrl-symbols", "\x00\x1c\x1f", []string{"ctrl+space", "ctrl+\\", "ctrl+_"}},
+		{"lone-escape", "\x1b", []string{"esc"}},
+		{"alt-letter", "\x1bx", []string{"alt+x"}},
+		{"alt-ctrl", "\x1b\x01", []string{"ctrl+alt+a"}},
+		{"alt-escape", "\x1b\x1b", []string{"alt+esc"}},
+		{"arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []string{"up", "down", "right", "left"}},
+		{"arrow-modifiers", "\x1b[1;2A\x1b[1;5D\x1b[1;3C", []string{"shift+up", "ctrl+left", "alt+right"}},
+		{"alt-arrow-rxvt", "\x1b\x1b[A", []string{"alt+up"}},
+		{"ss3", "\x1bOA\x1bOP\x1bOH", []string{"up", "f1", "home"}},
+		{"ss3-modifier", "\x1bO5P", []string{"ctrl+f1"}},
+		{"keypad", "\x1bOM\x1bOq", []string{"enter", "1"}},
+		{"tilde", "\x1b[2~\x1b[3~\x1b[5~\x1b[6~", []string{"insert", "delete", "pgup", "pgdown"}},
+		{"tilde-modifier", "\x1b[3;5~\x1b[15;2~", []string{"ctrl+delete", "shift+f5"}},
+		{"function-keys", "\x1b[11~\x1b[24~\x1b[34~", []string{"f1", "f12", "f20"}},
+		{"xterm-function-modifier", "\x1b[1;5P", []string{"ctrl+f1"}},
+		{"f3", "\x1b[R\x1b[1;2R", []string{"f3", "shift+f3"}},
+		{"home-end", "\x1b[H\x1b[F\x1b[1~\x1b[4~", []string{"home", "end", "home", "end"}},
+		{"back-tab", "\x1b[Z", []string{"shift+tab"}},
+		{"linux-console", "\x1b[[A\x1b[[E", []string{"f1", "f5"}},
+		{"truncated-csi", "\x1b[", []string{"alt+["}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := input.NewDecoder()
+			events := append(d.Decode([]byte(tt.input)), d.Flush()...)
+
+			var got []string
+			for _, ev := range events {
+				got = append(got, ev.(input.KeyEvent).String())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestDecoderCursorPosition tests that cursor position reports are not mistaken for F3.
+func TestDecoderCursorPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected input.Event
+	}{
+		{"report", "\x1b[12;40R", input.CursorPositionEvent{X: 39, Y: 11}},
+		{"first column", "\x1b[5;1R", input.CursorPositionEvent{X: 0, Y: 4}},
+		{"too many parameters", "\x1b[2;3;4R", input.UnknownEvent{Sequence: []byte("\x1b[2;3;4R")}},
+		{"missing column", "\x1b[7R", input.UnknownEvent{Sequence: []byte("\x1b[7R")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := input.NewDecoder()
+			events := append(d.Decode([]byte(tt.input)), d.Flush()...)
+			if len(events) != 1 || !reflect.DeepEqual(events[0], tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, events)
+			}
+		})
+	}
+}
+
+// TestDecoderSplitInput tests that sequences split across reads are reassembled.
+func TestDecoderSplitInput(t *testing.T) {
+	d := input.NewDecoder()
+
+	chunks := []string{"\x1b", "[1;", "5", "A", "\xe4\xb8", "\x96"}
+	var events []input.Event
+	for _, chunk := range chunks {
+		events = append(events, d.Decode([]byte(chunk))...)
+	}
+
+	expected := []input.Event{
+		input.KeyEvent{Key: input.KeyUp, Mod: input.ModCtrl},
+		input.KeyEvent{Key: input.KeyRune, Rune: '世'},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %v, got %v", expected, events)
+	}
+	if d.Pending() {
+		t.Errorf("Decoder should not have pending input")
+	}
+}
+
+// TestDecoderPending tests that a lone escape is held until flushed.
+func TestDecoderPending(t *testing.T) {
+	d := input.NewDecoder()
+
+	if events := d.Decode([]byte{0x1b}); len(events) != 0 {
+		t.Errorf("Expected no events before flush, got %v", events)
+	}
+	if !d.Pending() {
+		t.Fatalf("Expected pending input after a lone escape")
+	}
+
+	events := d.Flush()
+	if len(events) != 1 || events[0] != (input.KeyEvent{Key: input.KeyEscape}) {
+		t.Errorf("Expected a single escape key, got %v", events)
+	}
+}
+
+// TestDecoderUnknownSequence tests that unrecognized sequences are reported with their raw bytes.
+func TestDecoderUnknownSequence(t *testing.T) {
+	d := input.NewDecoder()
+	events := d.Decode([]byte("\x1b[99~x"))
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %v", events)
+	}
+	unknown, ok := events[0].(input.UnknownEvent)
+	if !ok || string(unknown.Sequence) != "\x1b[99~" {
+		t.Errorf("Expected unknown sequence, got %v", events[0])
+	}
+	if events[1] != (input.KeyEvent{Key: input.KeyRune, Rune: 'x'}) {
+		t.Errorf("Expected rune x, got %v", events[1])
+	}
+}
+
+// TestReaderEscapeTimeout tests that the reader reports a lone escape after the timeout.
+// This test feeds a pipe, which is not a terminal, so the terminal mode is left unchanged.
+func TestReaderEscapeTimeout(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "plan9" || runtime.GOOS == "windows" {
+		t.Skip("Waiting for pipe input is not supported on this platform")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reader := input.NewReader(r.Fd(), input.WithEscapeTimeout(20*time.Millisecond))
+	events := reader.Events(ctx)
+
+	if _, err := w.Write([]byte("q\x1b")); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	expected := []input.Event{
+		input.KeyEvent{Key: input.KeyRune, Rune: 'q'},
+		input.KeyEvent{Key: input.KeyEscape},
+	}
+	for _, want := range expected {
+		select {
+		case ev := <-events:
+			if ev != want {
+				t.Errorf("Expected %v, got %v", want, ev)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Timed out waiting for %v", want)
+		}
+	}
+
+	w.Close()
+	for range events {
+	}
+	if err := reader.Err(); err != nil {
+		t.Errorf("Expected no error at end of input, got %v", err)
+	}
+}
+
+// TestReaderCancel tests that the event channel is closed when the context is cancelled.
+func TestReaderCancel(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "plan9" || runtime.GOOS == "windows" {
+		t.Skip("Waiting for pipe input is not supported on this platform")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := input.NewReader(r.Fd()).Events(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("Expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the channel to close")
+	}
+}
+
+// TestDecoderBracketedPaste tests that bracketed paste is decoded into a single PasteEvent.
+// The paste is split across many chunks, including inside the end marker, and contains escape bytes and newlines.
+func TestDecoderBracketedPaste(t *testing.T) {
+	data := "a\x1b[200~line one\rline \x1b[Atwo\x1b[201~b"
+
+	for size := 1; size <= len(data); size++ {
+		d := input.NewDecoder()
+
+		var events []input.Event
+		for i := 0; i < len(data); i += size {
+			events = append(events, d.Decode([]byte(data[i:min(i+size, len(data))]))...)
+		}
+		events = append(events, d.Flush()...)
+
+		expected := []input.Event{
+			input.KeyEvent{Key: input.KeyRune, Rune: 'a'},
+			input.PasteEvent{Text: "line one\rline \x1b[Atwo"},
+			input.KeyEvent{Key: input.KeyRune, Rune: 'b'},
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("Chunk size %d: expected %v, got %v", size, expected, events)
+		}
+	}
+}
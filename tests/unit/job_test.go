@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestIsForegroundNonTerminal tests that non-terminal file descriptors are never in the foreground.
+// This test uses a pipe and a temporary file, neither of which has a process group.
+func TestIsForegroundNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	f, err := os.CreateTemp("", "probe-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	for _, fd := range []uintptr{r.Fd(), w.Fd(), f.Fd()} {
+		if probe.IsForeground(fd) {
+			t.Errorf("Non-terminal fd %d should not be reported as foreground", fd)
+		}
+		if probe.IsTostop(fd) {
+			t.Errorf("Non-terminal fd %d should not report TOSTOP", fd)
+		}
+	}
+}
+
+// TestIsForegroundStandardStreams tests that IsForeground is consistent with IsTerminal.
+// A process can only be in the foreground of a descriptor that is a terminal.
+func TestIsForegroundStandardStreams(t *testing.T) {
+	for _, fd := range []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()} {
+		foreground := probe.IsForeground(fd)
+		t.Logf("fd %d is foreground: %v", fd, foreground)
+
+		if foreground && !probe.IsTerminal(fd) {
+			t.Errorf("fd %d is foreground but not a terminal", fd)
+		}
+	}
+}
+
+// TestIsForegroundControllingTerminal tests that a process is in the foreground of its controlling terminal.
+// This test runs itself again in a new session on a pseudo-terminal, and checks that the pseudo-terminal
+// is not reported as foreground in this process, which it does not control.
+func TestIsForegroundControllingTerminal(t *testing.T) {
+	if os.Getenv(controllingVariable) != "" {
+		if !probe.IsForeground(os.Stdin.Fd()) {
+			t.Errorf("Controlling terminal should be reported as foreground")
+		}
+		return
+	}
+
+	master, slave := openPTY(t)
+	if probe.IsForeground(slave.Fd()) {
+		t.Errorf("Terminal of another session should not be reported as foreground")
+	}
+
+	stop := fakeTerminal(t, master, nil)
+	err := runControlling(t, slave, "TestIsForegroundControllingTerminal")
+	if output := stop(); err != nil {
+		t.Errorf("Child on its controlling terminal failed: %v\n%s", err, output)
+	}
+}
+
+// TestIsTostop tests that IsTostop reflects the TOSTOP flag of a pseudo-terminal.
+func TestIsTostop(t *testing.T) {
+	_, slave := openPTY(t)
+
+	if probe.IsTostop(slave.Fd()) {
+		t.Errorf("Expected TOSTOP to be clear on a new pseudo-terminal")
+	}
+	setTostop(t, slave)
+	if !probe.IsTostop(slave.Fd()) {
+		t.Errorf("Expected TOSTOP to be reported after setting it")
+	}
+}
+
+// TestWatchForeground tests that WatchForeground sends the initial state and closes on cancellation.
+func TestWatchForeground(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := probe.WatchForeground(ctx, w.Fd())
+
+	select {
+	case foreground := <-ch:
+		if foreground {
+			t.Errorf("Pipe should not be reported as foreground")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the initial state")
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("Expected channel to be closed after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the channel to close")
+	}
+}
@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+	"github.com/droqsic/probe/input"
+)
+
+// TestDecoderKitty tests decoding of kitty keyboard protocol sequences.
+func TestDecoderKitty(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected input.KeyEvent
+	}{
+		{"letter", "\x1b[97u", input.KeyEvent{Key: input.KeyRune, Rune: 'a'}},
+		{"ctrl-letter", "\x1b[105;5u", input.KeyEvent{Key: input.KeyRune, Rune: 'i', Mod: input.ModCtrl}},
+		{"escape", "\x1b[27u", input.KeyEvent{Key: input.KeyEscape}},
+		{"enter-release", "\x1b[13;1:3u", input.KeyEvent{Key: input.KeyEnter, Action: input.KeyRelease}},
+		{"repeat", "\x1b[97;1:2u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Action: input.KeyRepeat}},
+		{"alternates", "\x1b[97:65;2u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Mod: input.ModShift}},
+		{"text", "\x1b[97;2;65u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Mod: input.ModShift, Text: "A"}},
+		{"text-missing-mods", "\x1b[228;;228u", input.KeyEvent{Key: input.KeyRune, Rune: 'ä', Text: "ä"}},
+		{"text-multiple", "\x1b[97;;104:105u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Text: "hi"}},
+		{"super-hyper", "\x1b[97;25u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Mod: input.ModMeta | input.ModHyper}},
+		{"kitty-meta", "\x1b[97;33u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Mod: input.ModMeta}},
+		{"caps-lock", "\x1b[97;65u", input.KeyEvent{Key: input.KeyRune, Rune: 'a', Mod: input.ModCapsLock}},
+		{"f13", "\x1b[57376u", input.KeyEvent{Key: input.KeyF13}},
+		{"keypad", "\x1b[57400u\x1b[57414u", input.KeyEvent{Key: input.KeyEnter}},
+		{"legacy-release", "\x1b[1;5:3A", input.KeyEvent{Key: input.KeyUp, Mod: input.ModCtrl, Action: input.KeyRelease}},
+		{"tilde-repeat", "\x1b[3;1:2~", input.KeyEvent{Key: input.KeyDelete, Action: input.KeyRepeat}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := input.NewDecoder()
+			events := append(d.Decode([]byte(tt.input)), d.Flush()...)
+			if len(events) == 0 {
+				t.Fatalf("Expected %v, got no events", tt.expected)
+			}
+			if got := events[len(events)-1]; got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestDecoderKittyUnknown tests that codes without a matching key and the query reply are reported as unknown.
+func TestDecoderKittyUnknown(t *testing.T) {
+	for _, seq := range []string{"\x1b[57358u", "\x1b[?1u"} {
+		d := input.NewDecoder()
+		events := d.Decode([]byte(seq))
+		expected := []input.Event{input.UnknownEvent{Sequence: []byte(seq)}}
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("Expected %q to decode as unknown, got %v", seq, events)
+		}
+	}
+}
+
+// TestKeyEventString tests the description of repeat and release events.
+func TestKeyEventString(t *testing.T) {
+	ev := input.KeyEvent{Key: input.KeyRune, Rune: 'c', Mod: input.ModCtrl, Action: input.KeyRelease}
+	if got := ev.String(); got != "ctrl+c release" {
+		t.Errorf("Expected %q, got %q", "ctrl+c release", got)
+	}
+}
+
+// TestKittyKeyboardNonTerminal tests the kitty keyboard functions on a pipe.
+// The query fails with a NotTerminalError and pushing or popping writes nothing.
+func TestKittyKeyboardNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var notTerminal *probe.NotTerminalError
+	if _, _, err := probe.QueryKittyKeyboard(context.Background(), w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+
+	restore, err := probe.PushKittyKeyboard(w.Fd(), probe.KittyDisambiguate)
+	if err != nil {
+		t.Fatalf("PushKittyKeyboard failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring failed: %v", err)
+	}
+	if err := probe.PopKittyKeyboard(w.Fd(), 1); err != nil {
+		t.Errorf("PopKittyKeyboard failed: %v", err)
+	}
+	w.Close()
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); n != 0 {
+		t.Errorf("Expected nothing to be written to the pipe, got %q", buf[:n])
+	}
+}
+
+// TestQueryKittyKeyboard tests the query against a fake terminal with and without protocol support.
+func TestQueryKittyKeyboard(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies map[string]string
+		flags   probe.KittyKeyboardFlags
+		ok      bool
+	}{
+		{"supported", map[string]string{"\x1b[?u": "\x1b[?5u", "\x1b[c": "\x1b[?62;22c"}, probe.KittyDisambiguate | probe.KittyReportAlternates, true},
+		{"unsupported", map[string]string{"\x1b[c": "\x1b[?1;2c"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, tt.replies)
+			defer stop()
+
+			flags, ok, err := probe.QueryKittyKeyboard(context.Background(), slave.Fd())
+			if err != nil {
+				t.Fatalf("QueryKittyKeyboard failed: %v", err)
+			}
+			if flags != tt.flags || ok != tt.ok {
+				t.Errorf("Expected %d, %v, got %d, %v", tt.flags, tt.ok, flags, ok)
+			}
+		})
+	}
+}
+
+// TestQueryKittyKeyboardNoReply tests that a terminal that never answers yields ErrNoReply once the context expires.
+func TestQueryKittyKeyboardNoReply(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := probe.QueryKittyKeyboard(ctx, slave.Fd()); !errors.Is(err, probe.ErrNoReply) {
+		t.Errorf("Expected ErrNoReply, got %v", err)
+	}
+}
+
+// TestPushKittyKeyboard tests the sequences written to push and pop flags.
+func TestPushKittyKeyboard(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	restore, err := probe.PushKittyKeyboard(slave.Fd(), probe.KittyDisambiguate|probe.KittyReportEvents)
+	if err != nil {
+		t.Fatalf("PushKittyKeyboard failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring failed: %v", err)
+	}
+	if err := probe.PopKittyKeyboard(slave.Fd(), 2); err != nil {
+		t.Errorf("PopKittyKeyboard failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	expected := "\x1b[>3u\x1b[<1u\x1b[<2u"
+	if got := string(stop()); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestReaderKittyKeyboard tests that the reader pushes the flags only when the terminal answers the query.
+func TestReaderKittyKeyboard(t *testing.T) {
+	tests := []struct {
+		name     string
+		replies  map[string]string
+		expected string
+	}{
+		{"supported", map[string]string{"\x1b[?u": "\x1b[?0u", "\x1b[c": "\x1b[?62c"}, "\x1b[?u\x1b[c\x1b[>1u\x1b[<1u"},
+		{"fallback", map[string]string{"\x1b[c": "\x1b[?62c"}, "\x1b[?u\x1b[c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, tt.replies)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			r := input.NewReader(slave.Fd(), input.WithKittyKeyboard(probe.KittyDisambiguate))
+			events := r.Events(ctx)
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			for range events {
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if err := r.Err(); err != nil {
+				t.Errorf("Reader failed: %v", err)
+			}
+			if got := string(stop()); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
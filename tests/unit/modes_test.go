@@ -0,0 +1,180 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestModesNonTerminal tests that mode changes write nothing to a non-terminal file descriptor.
+// This test enables and disables every mode on a pipe and checks that the pipe stays empty.
+func TestModesNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	fd := w.Fd()
+	restore, err := probe.EnableBracketedPaste(fd)
+	if err != nil {
+		t.Fatalf("EnableBracketedPaste failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring bracketed paste failed: %v", err)
+	}
+	if err := probe.DisableBracketedPaste(fd); err != nil {
+		t.Errorf("DisableBracketedPaste failed: %v", err)
+	}
+
+	restore, err = probe.EnableMouse(fd, probe.MouseAnyEvent, probe.MouseEncodingSGR)
+	if err != nil {
+		t.Fatalf("EnableMouse failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring mouse tracking failed: %v", err)
+	}
+	if err := probe.DisableMouse(fd); err != nil {
+		t.Errorf("DisableMouse failed: %v", err)
+	}
+
+	restore, err = probe.EnableFocusReporting(fd)
+	if err != nil {
+		t.Fatalf("EnableFocusReporting failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring focus reporting failed: %v", err)
+	}
+	if err := probe.DisableFocusReporting(fd); err != nil {
+		t.Errorf("DisableFocusReporting failed: %v", err)
+	}
+
+	w.Close()
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); n != 0 {
+		t.Errorf("Expected nothing to be written to the pipe, got %q", buf[:n])
+	}
+}
+
+// TestEnableMouseUnknownMode tests that tracking modes and encodings other than the constants are refused
+// and that nothing is written to the terminal for them.
+func TestEnableMouseUnknownMode(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	fd := slave.Fd()
+
+	tests := []struct {
+		name     string
+		tracking probe.MouseTracking
+		encoding probe.MouseEncoding
+	}{
+		{"zero tracking", 0, probe.MouseEncodingSGR},
+		{"unknown tracking", 1004, probe.MouseEncodingX10},
+		{"unknown encoding", probe.MouseNormal, 1005},
+	}
+
+	session := probe.NewSession(fd)
+	defer session.Close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := probe.EnableMouse(fd, tt.tracking, tt.encoding); !errors.Is(err, probe.ErrUnknownMouseMode) {
+				t.Errorf("Expected ErrUnknownMouseMode from EnableMouse, got %v", err)
+			}
+			if err := session.EnableMouse(tt.tracking, tt.encoding); !errors.Is(err, probe.ErrUnknownMouseMode) {
+				t.Errorf("Expected ErrUnknownMouseMode from Session.EnableMouse, got %v", err)
+			}
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := stop(); len(got) != 0 {
+		t.Errorf("Expected nothing to be written, got %q", got)
+	}
+}
+
+// TestQueryModes tests parsing DECRQM replies for several modes batched into one round trip.
+func TestQueryModes(t *testing.T) {
+	master, slave := openPTY(t)
+	request := "\x1b[?2004$p\x1b[?1004$p\x1b[?2026$p\x1b[?2027$p\x1b[?25$p\x1b[c"
+	reply := "\x1b[?2004;1$y\x1b[?1004;2$y\x1b[?2026;0$y\x1b[?2027;4$y\x1b[?25;3$y\x1b[?62c"
+	stop := fakeTerminal(t, master, map[string]string{request: reply})
+	defer stop()
+
+	got, err := probe.QueryModes(context.Background(), slave.Fd(), 2004, 1004, 2026, 2027, 25)
+	if err != nil {
+		t.Fatalf("QueryModes failed: %v", err)
+	}
+	expected := map[int]probe.ModeStatus{
+		2004: probe.ModeSet,
+		1004: probe.ModeReset,
+		2026: probe.ModeNotRecognized,
+		2027: probe.ModePermanentlyReset,
+		25:   probe.ModePermanentlySet,
+	}
+	for mode, status := range expected {
+		if got[mode] != status {
+			t.Errorf("Expected mode %d to be %v, got %v", mode, status, got[mode])
+		}
+	}
+}
+
+// TestQueryMode tests a single query, including a terminal that ignores DECRQM and one that never answers.
+func TestQueryMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		replies  map[string]string
+		expected probe.ModeStatus
+		err      error
+	}{
+		{"reset", map[string]string{"\x1b[?2004$p\x1b[c": "\x1b[?2004;2$y\x1b[?62c"}, probe.ModeReset, nil},
+		{"no DECRQM", map[string]string{"\x1b[?2004$p\x1b[c": "\x1b[?1;2c"}, probe.ModeNotRecognized, nil},
+		{"no reply", nil, probe.ModeNotRecognized, probe.ErrNoReply},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, tt.replies)
+			defer stop()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			got, err := probe.QueryMode(ctx, slave.Fd(), 2004)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestModeStatus tests the names of mode states and which of them can be changed.
+func TestModeStatus(t *testing.T) {
+	tests := []struct {
+		status     probe.ModeStatus
+		name       string
+		changeable bool
+	}{
+		{probe.ModeNotRecognized, "not-recognized", false},
+		{probe.ModeSet, "set", true},
+		{probe.ModeReset, "reset", true},
+		{probe.ModePermanentlySet, "permanently-set", false},
+		{probe.ModePermanentlyReset, "permanently-reset", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.name {
+			t.Errorf("Expected %q, got %q", tt.name, got)
+		}
+		if got := tt.status.Changeable(); got != tt.changeable {
+			t.Errorf("Expected %v to be changeable %v, got %v", tt.status, tt.changeable, got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/droqsic/probe/input"
+)
+
+// TestDecoderMouseSGR tests decoding of SGR (1006) mouse reports.
+func TestDecoderMouseSGR(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected input.MouseEvent
+	}{
+		{"left-press", "\x1b[<0;10;5M", input.MouseEvent{X: 9, Y: 4, Button: input.MouseLeft, Action: input.MousePress}},
+		{"left-release", "\x1b[<0;10;5m", input.MouseEvent{X: 9, Y: 4, Button: input.MouseLeft, Action: input.MouseRelease}},
+		{"right-press", "\x1b[<2;1;1M", input.MouseEvent{X: 0, Y: 0, Button: input.MouseRight, Action: input.MousePress}},
+		{"drag", "\x1b[<32;300;200M", input.MouseEvent{X: 299, Y: 199, Button: input.MouseLeft, Action: input.MouseMotion}},
+		{"hover", "\x1b[<35;3;4M", input.MouseEvent{X: 2, Y: 3, Button: input.MouseNone, Action: input.MouseMotion}},
+		{"wheel-up", "\x1b[<64;1;1M", input.MouseEvent{Button: input.MouseWheelUp, Action: input.MousePress}},
+		{"wheel-down", "\x1b[<65;1;1M", input.MouseEvent{Button: input.MouseWheelDown, Action: input.MousePress}},
+		{"backward", "\x1b[<128;1;1M", input.MouseEvent{Button: input.MouseBackward, Action: input.MousePress}},
+		{"forward", "\x1b[<129;1;1M", input.MouseEvent{Button: input.MouseForward, Action: input.MousePress}},
+		{"button-10", "\x1b[<130;1;1M", input.MouseEvent{Button: input.MouseButton10, Action: input.MousePress}},
+		{"button-11", "\x1b[<131;1;1m", input.MouseEvent{Button: input.MouseButton11, Action: input.MouseRelease}},
+		{"ctrl-shift-click", "\x1b[<20;1;1M", input.MouseEvent{Button: input.MouseLeft, Action: input.MousePress, Mod: input.ModCtrl | input.ModShift}},
+		{"alt-middle", "\x1b[<9;1;1M", input.MouseEvent{Button: input.MouseMiddle, Action: input.MousePress, Mod: input.ModAlt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := input.NewDecoder().Decode([]byte(tt.input))
+			if len(events) != 1 || events[0] != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, events)
+			}
+			if tt.expected.Button.String() == "unknown" {
+				t.Errorf("Expected button %d to have a name", tt.expected.Button)
+			}
+		})
+	}
+}
+
+// TestDecoderMouseX10 tests decoding of legacy mouse reports, where values are raw bytes offset by 32.
+func TestDecoderMouseX10(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected input.MouseEvent
+	}{
+		{"left-press", []byte{0x1b, '[', 'M', 32, 33 + 9, 33 + 4}, input.MouseEvent{X: 9, Y: 4, Button: input.MouseLeft, Action: input.MousePress}},
+		{"release", []byte{0x1b, '[', 'M', 32 + 3, 33, 33}, input.MouseEvent{Button: input.MouseNone, Action: input.MouseRelease}},
+		{"drag", []byte{0x1b, '[', 'M', 32 + 32 + 2, 33, 33}, input.MouseEvent{Button: input.MouseRight, Action: input.MouseMotion}},
+		{"wheel-down", []byte{0x1b, '[', 'M', 32 + 65, 33, 33}, input.MouseEvent{Button: input.MouseWheelDown, Action: input.MousePress}},
+		{"large-coordinates", []byte{0x1b, '[', 'M', 32, 255, 200}, input.MouseEvent{X: 222, Y: 167, Button: input.MouseLeft, Action: input.MousePress}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := input.NewDecoder().Decode(tt.input)
+			if len(events) != 1 || events[0] != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, events)
+			}
+		})
+	}
+}
+
+// TestDecoderMouseSplit tests that mouse reports split across reads are reassembled.
+func TestDecoderMouseSplit(t *testing.T) {
+	d := input.NewDecoder()
+
+	var events []input.Event
+	for _, chunk := range [][]byte{{0x1b, '[', 'M'}, {32, 40}, {40}, []byte("\x1b[<0;1"), []byte("2;7m")} {
+		events = append(events, d.Decode(chunk)...)
+	}
+
+	expected := []input.Event{
+		input.MouseEvent{X: 7, Y: 7, Button: input.MouseLeft, Action: input.MousePress},
+		input.MouseEvent{X: 11, Y: 6, Button: input.MouseLeft, Action: input.MouseRelease},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, events)
+	}
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestTerminalNameNonTerminal tests that TerminalName rejects non-terminal file descriptors.
+// This test uses a pipe and a temporary file and checks that a *NotTerminalError is returned.
+func TestTerminalNameNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	f, err := os.CreateTemp("", "probe-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	probe.ClearCache()
+
+	for _, fd := range []uintptr{r.Fd(), f.Fd()} {
+		name, err := probe.TerminalName(fd)
+
+		var notTerminal *probe.NotTerminalError
+		if !errors.As(err, &notTerminal) {
+			t.Errorf("Expected *NotTerminalError for fd %d, got name %q and error %v", fd, name, err)
+		}
+	}
+}
+
+// TestTerminalNameConsistency tests that TerminalName returns the same result when cached.
+// It checks the standard file descriptors, which may or may not be terminals.
+func TestTerminalNameConsistency(t *testing.T) {
+	for _, fd := range []uintptr{os.Stdin.Fd(), os.Stdout.Fd(), os.Stderr.Fd()} {
+		probe.ClearCache()
+
+		first, firstErr := probe.TerminalName(fd)
+		second, secondErr := probe.TerminalName(fd)
+		t.Logf("fd %d terminal name: %q (%v)", fd, first, firstErr)
+
+		if first != second || (firstErr == nil) != (secondErr == nil) {
+			t.Errorf("Inconsistent results for fd %d: %q/%v and %q/%v", fd, first, firstErr, second, secondErr)
+		}
+		if firstErr == nil && !probe.IsTerminal(fd) {
+			t.Errorf("fd %d has a terminal name but is not a terminal", fd)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestReadPasswordNotTerminal tests that ReadPassword refuses non-terminal file descriptors.
+// This test uses a pipe and checks that a *NotTerminalError is returned.
+func TestReadPasswordNotTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	_, err = probe.ReadPassword(r.Fd())
+
+	var notTerminal *probe.NotTerminalError
+	if !errors.As(err, &notTerminal) {
+		t.Fatalf("Expected *NotTerminalError, got %v", err)
+	}
+	if notTerminal.Fd != r.Fd() {
+		t.Errorf("Expected error for fd %d, got %d", r.Fd(), notTerminal.Fd)
+	}
+}
+
+// TestReadPasswordLineEditing tests backspace and Ctrl-U handling.
+// This test writes edited input to a pipe and reads it back with AllowNonTerminal.
+func TestReadPasswordLineEditing(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "plan9" {
+		t.Skip("Pipe reads are not supported on this platform")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "secret\n", "secret"},
+		{"carriage-return", "secret\r", "secret"},
+		{"backspace", "secrex\x7ft\n", "secret"},
+		{"ctrl-h", "secrex\bt\n", "secret"},
+		{"kill-line", "wrong\x15secret\n", "secret"},
+		{"multibyte-backspace", "sécré\x7fet\n", "sécret"},
+		{"control-ignored", "sec\x01ret\n", "secret"},
+		{"eof-after-data", "secret", "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("Failed to create pipe: %v", err)
+			}
+			defer r.Close()
+
+			if _, err := w.WriteString(tt.input); err != nil {
+				t.Fatalf("Failed to write input: %v", err)
+			}
+			w.Close()
+
+			password, err := probe.ReadPassword(r.Fd(), probe.AllowNonTerminal())
+			if err != nil {
+				t.Fatalf("ReadPassword failed: %v", err)
+			}
+			if string(password) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, password)
+			}
+		})
+	}
+}
+
+// TestReadPasswordTerminal tests that echo is off during the read and restored after it,
+// and that the mask is echoed and erased by backspace.
+// This test reads from a pseudo-terminal and types into its master side.
+func TestReadPasswordTerminal(t *testing.T) {
+	master, slave := openPTY(t)
+
+	type result struct {
+		password []byte
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		password, err := probe.ReadPassword(slave.Fd(), probe.WithMask('*'))
+		done <- result{password, err}
+	}()
+
+	waitForEcho(t, slave, false)
+	attrs, err := probe.Attributes(slave.Fd())
+	if err != nil {
+		t.Fatalf("Attributes failed: %v", err)
+	}
+	if attrs.Signals {
+		t.Errorf("Expected signal generation to be off while reading")
+	}
+
+	if _, err := master.WriteString("ab\x7fc\r"); err != nil {
+		t.Fatalf("Failed to type input: %v", err)
+	}
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for ReadPassword")
+	}
+	if res.err != nil {
+		t.Fatalf("ReadPassword failed: %v", res.err)
+	}
+	if string(res.password) != "ac" {
+		t.Errorf("Expected %q, got %q", "ac", res.password)
+	}
+
+	if echoed, expected := readMaster(t, master, len("**\b \b*")), "**\b \b*"; echoed != expected {
+		t.Errorf("Expected echo %q, got %q", expected, echoed)
+	}
+
+	attrs, err = probe.Attributes(slave.Fd())
+	if err != nil {
+		t.Fatalf("Attributes failed: %v", err)
+	}
+	if !attrs.Echo || !attrs.Signals {
+		t.Errorf("Expected echo and signals to be restored, got echo %v and signals %v", attrs.Echo, attrs.Signals)
+	}
+}
+
+// TestReadPasswordOutput tests that WithOutput sends the mask to another file descriptor
+// and that a mask that cannot be written is reported instead of dropped.
+func TestReadPasswordOutput(t *testing.T) {
+	master, slave := openPTY(t)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		password, err := probe.ReadPassword(slave.Fd(), probe.WithMask('*'), probe.WithOutput(w.Fd()))
+		if err == nil && string(password) != "ab" {
+			err = fmt.Errorf("expected %q, got %q", "ab", password)
+		}
+		done <- err
+	}()
+
+	waitForEcho(t, slave, false)
+	if _, err := master.WriteString("ab\r"); err != nil {
+		t.Fatalf("Failed to type input: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ReadPassword failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for ReadPassword")
+	}
+
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatalf("Failed to read mask: %v", err)
+	}
+	if string(buf) != "**" {
+		t.Errorf("Expected mask %q, got %q", "**", buf)
+	}
+
+	// The read end of the pipe cannot be written to.
+	go func() {
+		_, err := probe.ReadPassword(slave.Fd(), probe.WithMask('*'), probe.WithOutput(r.Fd()))
+		done <- err
+	}()
+	waitForEcho(t, slave, false)
+	if _, err := master.WriteString("a"); err != nil {
+		t.Fatalf("Failed to type input: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("Expected an error writing the mask")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for ReadPassword")
+	}
+	waitForEcho(t, slave, true)
+}
+
+// TestReadPasswordTerminalInterrupt tests that Ctrl-C on a terminal returns ErrInterrupted instead of raising SIGINT.
+func TestReadPasswordTerminalInterrupt(t *testing.T) {
+	master, slave := openPTY(t)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := probe.ReadPassword(slave.Fd())
+		done <- err
+	}()
+
+	waitForEcho(t, slave, false)
+	if _, err := master.WriteString("ab\x03"); err != nil {
+		t.Fatalf("Failed to type input: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, probe.ErrInterrupted) {
+			t.Errorf("Expected ErrInterrupted, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for ReadPassword")
+	}
+	waitForEcho(t, slave, true)
+}
+
+// waitForEcho waits until echo on the terminal is in the expected state, as it is once ReadPassword
+// has disabled it or restored it.
+func waitForEcho(t *testing.T, slave *os.File, echo bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		attrs, err := probe.Attributes(slave.Fd())
+		if err != nil {
+			t.Fatalf("Attributes failed: %v", err)
+		}
+		if attrs.Echo == echo {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for echo to be %v", echo)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// readMaster reads n bytes of output from the master side of a pseudo-terminal, failing the test on timeout.
+func readMaster(t *testing.T, master *os.File, n int) string {
+	t.Helper()
+	if err := master.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("Failed to set read deadline: %v", err)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(master, buf); err != nil {
+		t.Fatalf("Failed to read terminal output %q: %v", buf, err)
+	}
+	return string(buf)
+}
+
+// TestReadPasswordEOF tests that an empty input returns io.EOF.
+func TestReadPasswordEOF(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "plan9" {
+		t.Skip("Pipe reads are not supported on this platform")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	w.Close()
+
+	if _, err := probe.ReadPassword(r.Fd(), probe.AllowNonTerminal()); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+// TestReadPasswordContextCancel tests that ReadPasswordContext stops when the context expires.
+// This test uses a pipe that never receives input.
+func TestReadPasswordContextCancel(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "plan9" || runtime.GOOS == "windows" {
+		t.Skip("Waiting for pipe input is not supported on this platform")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = probe.ReadPasswordContext(ctx, r.Fd(), probe.AllowNonTerminal())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestWipe tests that Wipe zeroes the buffer.
+func TestWipe(t *testing.T) {
+	b := []byte("secret")
+	probe.Wipe(b)
+
+	for i, c := range b {
+		if c != 0 {
+			t.Errorf("Byte %d was not wiped: %d", i, c)
+		}
+	}
+}
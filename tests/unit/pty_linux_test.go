@@ -0,0 +1,85 @@
+//go:build linux
+// +build linux
+
+package unit
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/droqsic/probe"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair for tests that need a real terminal.
+// The master is non-blocking so that closing it interrupts a pending read.
+// The cache is cleared on open and close because file descriptor numbers are reused between tests.
+// It skips the test if the system has no pseudo-terminals available.
+func openPTY(t *testing.T) (master, slave *os.File) {
+	t.Helper()
+
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Skipf("Pseudo-terminals are not available: %v", err)
+	}
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		t.Fatalf("Failed to unlock pseudo-terminal: %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		t.Fatalf("Failed to get pseudo-terminal number: %v", err)
+	}
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		t.Fatalf("Failed to open pseudo-terminal: %v", err)
+	}
+
+	probe.ClearCache()
+	t.Cleanup(func() {
+		slave.Close()
+		master.Close()
+		probe.ClearCache()
+	})
+	return master, slave
+}
+
+// setWindowSize sets the size of a pseudo-terminal in cells and pixels, as a terminal emulator does on resize.
+func setWindowSize(t *testing.T, master *os.File, columns, rows, width, height int) {
+	t.Helper()
+	size := &unix.Winsize{Col: uint16(columns), Row: uint16(rows), Xpixel: uint16(width), Ypixel: uint16(height)}
+	if err := unix.IoctlSetWinsize(int(master.Fd()), unix.TIOCSWINSZ, size); err != nil {
+		t.Fatalf("Failed to set window size: %v", err)
+	}
+}
+
+// setTostop sets the TOSTOP flag of a pseudo-terminal, as "stty tostop" does.
+func setTostop(t *testing.T, slave *os.File) {
+	t.Helper()
+	termios, err := unix.IoctlGetTermios(int(slave.Fd()), unix.TCGETS)
+	if err != nil {
+		t.Fatalf("Failed to get terminal attributes: %v", err)
+	}
+	termios.Lflag |= unix.TOSTOP
+	if err := unix.IoctlSetTermios(int(slave.Fd()), unix.TCSETS, termios); err != nil {
+		t.Fatalf("Failed to set terminal attributes: %v", err)
+	}
+}
+
+// runControlling runs the named test in a new session whose controlling terminal is the slave,
+// as a shell starts a job in the foreground. The child sees controllingVariable set and uses the slave
+// as its standard streams; it returns an error if the test fails in the child.
+func runControlling(t *testing.T, slave *os.File, name string) error {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^"+name+"$")
+	cmd.Env = append(os.Environ(), controllingVariable+"=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	return cmd.Run()
+}
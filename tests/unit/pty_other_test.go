@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package unit
+
+import (
+	"os"
+	"testing"
+)
+
+// openPTY skips the test, because opening pseudo-terminals is only implemented for Linux.
+func openPTY(t *testing.T) (master, slave *os.File) {
+	t.Helper()
+	t.Skip("Pseudo-terminals are only opened on Linux")
+	return nil, nil
+}
+
+// setWindowSize is never reached, because openPTY skips the test.
+func setWindowSize(t *testing.T, master *os.File, columns, rows, width, height int) {
+	t.Helper()
+}
+
+// setTostop is never reached, because openPTY skips the test.
+func setTostop(t *testing.T, slave *os.File) {
+	t.Helper()
+}
+
+// runControlling is never reached, because openPTY skips the test.
+func runControlling(t *testing.T, slave *os.File, name string) error {
+	t.Helper()
+	return nil
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// controllingVariable is set in the environment of tests that runControlling starts on a controlling terminal.
+const controllingVariable = "PROBE_TEST_CONTROLLING"
+
+// fakeTerminal answers queries written to the slave side of a pseudo-terminal, like a terminal emulator would.
+// Each request found in the output is answered with its reply, in order; everything else is recorded.
+// The returned function stops the fake terminal and returns everything the program wrote.
+func fakeTerminal(t *testing.T, master *os.File, replies map[string]string) (stop func() []byte) {
+	t.Helper()
+
+	var output []byte
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 1024)
+		var pending []byte
+		for {
+			n, err := master.Read(buf)
+			if n > 0 {
+				output = append(output, buf[:n]...)
+				pending = append(pending, buf[:n]...)
+				var answer []byte
+				for i := 0; i < len(pending); {
+					matched := false
+					for request, reply := range replies {
+						if bytes.HasPrefix(pending[i:], []byte(request)) {
+							answer = append(answer, reply...)
+							i += len(request)
+							matched = true
+							break
+						}
+					}
+					if !matched {
+						i++
+					}
+				}
+				pending = pending[:0]
+				if len(answer) > 0 {
+					master.Write(answer)
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() []byte {
+		master.Close()
+		<-done
+		return output
+	}
+}
+
+// emulatorVariables lists the environment variables that identify terminal emulators and multiplexers.
+var emulatorVariables = []string{
+	"TERM", "TERM_PROGRAM", "TERM_PROGRAM_VERSION", "LC_TERMINAL", "LC_TERMINAL_VERSION", "KITTY_WINDOW_ID",
+	"WEZTERM_EXECUTABLE", "ALACRITTY_WINDOW_ID", "ALACRITTY_LOG", "WT_SESSION", "KONSOLE_VERSION", "VTE_VERSION",
+	"TERMINAL_EMULATOR", "INSIDE_EMACS", "XTERM_VERSION", "TMUX", "STY", "FORCE_HYPERLINK",
+}
+
+// setEmulator makes the environment look like the given terminal emulator for the rest of the test.
+// Every variable that identifies an emulator is unset before vars are set; all are restored when the test ends.
+func setEmulator(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for _, name := range emulatorVariables {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+}
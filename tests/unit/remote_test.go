@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// remoteVariables lists the environment variables RemoteSession reads.
+var remoteVariables = []string{
+	"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY", "CODESPACES", "GITPOD_WORKSPACE_ID", "CLOUD_SHELL",
+	"REMOTE_CONTAINERS", "VSCODE_IPC_HOOK_CLI",
+}
+
+// setRemote makes the environment look like the given session for the rest of the test.
+// Every variable RemoteSession reads is unset before vars are set; all are restored when the test ends.
+func setRemote(t *testing.T, vars map[string]string) {
+	t.Helper()
+	for _, name := range remoteVariables {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+}
+
+// fakeProc builds a fake procfs tree in which the ancestors of the test process have the given command names,
+// parent first, and returns its root.
+func fakeProc(t *testing.T, names ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	pid := os.Getppid()
+	for i, name := range names {
+		ppid := 1
+		if i < len(names)-1 {
+			ppid = 100000 + i
+		}
+		dir := filepath.Join(root, strconv.Itoa(pid))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		stat := strconv.Itoa(pid) + " (" + name + ") S " + strconv.Itoa(ppid) + " 0 0 0\n"
+		if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+			t.Fatalf("Failed to write stat: %v", err)
+		}
+		pid = ppid
+	}
+	return root
+}
+
+// TestRemoteSession tests the detection of SSH, mosh and remote development environments.
+func TestRemoteSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		ancestors []string
+		expected  probe.Remote
+		ok        bool
+	}{
+		{"local", nil, []string{"bash", "login"}, probe.Remote{}, false},
+		{"ssh connection", map[string]string{"SSH_CONNECTION": "192.0.2.7 50122 198.51.100.1 22"}, nil,
+			probe.Remote{SSH: true, ClientAddress: "192.0.2.7", ClientPort: 50122, ServerAddress: "198.51.100.1", ServerPort: 22}, true},
+		{"ssh client", map[string]string{"SSH_CLIENT": "2001:db8::7 50122 2222"}, nil,
+			probe.Remote{SSH: true, ClientAddress: "2001:db8::7", ClientPort: 50122, ServerPort: 2222}, true},
+		{"sudo", nil, []string{"bash", "sudo", "bash", "sshd-session"}, probe.Remote{SSH: true}, true},
+		{"mosh", map[string]string{"SSH_CONNECTION": "192.0.2.7 50122 198.51.100.1 22"}, []string{"zsh", "mosh-server"},
+			probe.Remote{SSH: true, Mosh: true, ClientAddress: "192.0.2.7", ClientPort: 50122, ServerAddress: "198.51.100.1", ServerPort: 22}, true},
+		{"codespaces", map[string]string{"CODESPACES": "true"}, nil, probe.Remote{IDE: probe.RemoteIDECodespaces}, true},
+		{"vscode over ssh", map[string]string{"SSH_CLIENT": "192.0.2.7 1 22", "VSCODE_IPC_HOOK_CLI": "/tmp/vscode.sock"}, nil,
+			probe.Remote{SSH: true, IDE: probe.RemoteIDEVSCode, ClientAddress: "192.0.2.7", ClientPort: 1, ServerPort: 22}, true},
+		{"vscode locally", map[string]string{"VSCODE_IPC_HOOK_CLI": "/tmp/vscode.sock"}, nil, probe.Remote{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRemote(t, tt.env)
+			root := fakeProc(t, tt.ancestors...)
+
+			got, ok := probe.RemoteSession(os.Stdin.Fd(), probe.WithProcRoot(root))
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("Expected %+v (%v), got %+v (%v)", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+// TestRemoteSessionSSHTTY tests matching SSH_TTY against the terminal behind the file descriptor.
+func TestRemoteSessionSSHTTY(t *testing.T) {
+	_, slave := openPTY(t)
+	name, err := probe.TerminalName(slave.Fd())
+	if err != nil {
+		t.Fatalf("TerminalName failed: %v", err)
+	}
+	root := fakeProc(t)
+
+	setRemote(t, map[string]string{"SSH_TTY": name})
+	if got, ok := probe.RemoteSession(slave.Fd(), probe.WithProcRoot(root)); !ok || !got.SSH || !got.OnSSHTTY || got.SSHTTY != name {
+		t.Errorf("Expected the terminal to be the SSH terminal %s, got %+v", name, got)
+	}
+
+	setRemote(t, map[string]string{"SSH_TTY": "/dev/pts/999999"})
+	if got, _ := probe.RemoteSession(slave.Fd(), probe.WithProcRoot(root)); !got.SSH || got.OnSSHTTY {
+		t.Errorf("Expected the terminal not to be the SSH terminal, got %+v", got)
+	}
+}
@@ -0,0 +1,171 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSessionNonTerminal tests that a session on a pipe writes nothing and refuses raw mode.
+func TestSessionNonTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	session := probe.NewSession(w.Fd())
+	var notTerminal *probe.NotTerminalError
+	if err := session.MakeRaw(); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+	if err := session.HideCursor(); err != nil {
+		t.Errorf("HideCursor failed: %v", err)
+	}
+	if err := session.EnableMouse(probe.MouseNormal, probe.MouseEncodingSGR); err != nil {
+		t.Errorf("EnableMouse failed: %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+
+	w.Close()
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); n != 0 {
+		t.Errorf("Expected nothing to be written to the pipe, got %q", buf[:n])
+	}
+}
+
+// TestSessionClose tests that changes are reverted in reverse order and that a closed session refuses changes.
+func TestSessionClose(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	fd := slave.Fd()
+
+	session := probe.NewSession(fd)
+	if err := session.MakeRaw(); err != nil {
+		t.Fatalf("MakeRaw failed: %v", err)
+	}
+	if attrs, _ := probe.Attributes(fd); attrs.Echo {
+		t.Errorf("Expected echo to be off in raw mode")
+	}
+	if err := session.HideCursor(); err != nil {
+		t.Errorf("HideCursor failed: %v", err)
+	}
+	if err := session.EnableBracketedPaste(); err != nil {
+		t.Errorf("EnableBracketedPaste failed: %v", err)
+	}
+	if err := session.EnableMouse(probe.MouseButton, probe.MouseEncodingSGR); err != nil {
+		t.Errorf("EnableMouse failed: %v", err)
+	}
+
+	if err := session.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if attrs, _ := probe.Attributes(fd); !attrs.Echo {
+		t.Errorf("Expected echo to be restored")
+	}
+	if err := session.HideCursor(); !errors.Is(err, probe.ErrSessionClosed) {
+		t.Errorf("Expected ErrSessionClosed, got %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	expected := "\x1b[?25l\x1b[?2004h\x1b[?1002;1006h\x1b[?1002;1006l\x1b[?2004l\x1b[?25h"
+	if got := string(stop()); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestSessionRecover tests that a panic reverts the changes before it continues.
+func TestSessionRecover(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	session := probe.NewSession(slave.Fd())
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to continue, got %v", r)
+			}
+		}()
+		defer session.Recover()
+
+		if err := session.HideCursor(); err != nil {
+			t.Errorf("HideCursor failed: %v", err)
+		}
+		panic("boom")
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	expected := "\x1b[?25l\x1b[?25h"
+	if got := string(stop()); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestSessionForwardSignals tests that a forwarded termination signal reverts the changes and reaches the program once.
+func TestSessionForwardSignals(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	ch := make(chan os.Signal, 4)
+	session := probe.NewSession(slave.Fd(), probe.ForwardSignals(ch))
+	defer session.Close()
+	if err := session.HideCursor(); err != nil {
+		t.Fatalf("HideCursor failed: %v", err)
+	}
+
+	sendInterrupt(t)
+	select {
+	case sig := <-ch:
+		if sig != os.Interrupt {
+			t.Errorf("Expected SIGINT to be forwarded, got %v", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the forwarded signal")
+	}
+	select {
+	case <-ch:
+		t.Errorf("Expected SIGINT to be forwarded once")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := session.HideCursor(); !errors.Is(err, probe.ErrSessionClosed) {
+		t.Errorf("Expected the session to be closed, got %v", err)
+	}
+	if got, expected := string(stop()), "\x1b[?25l\x1b[?25h"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestSessionIgnoredSignal tests that a session leaves a signal the process ignores alone.
+func TestSessionIgnoredSignal(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	ignoreInterrupt(t)
+
+	session := probe.NewSession(slave.Fd())
+	defer session.Close()
+	if err := session.HideCursor(); err != nil {
+		t.Fatalf("HideCursor failed: %v", err)
+	}
+
+	sendInterrupt(t)
+	time.Sleep(100 * time.Millisecond)
+
+	if !signal.Ignored(os.Interrupt) {
+		t.Errorf("Expected SIGINT to stay ignored")
+	}
+	if got, expected := string(stop()), "\x1b[?25l"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,95 @@
+package unit
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// sendInterrupt sends SIGINT to the test process, skipping the test where that is not possible.
+func sendInterrupt(t *testing.T) {
+	t.Helper()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find own process: %v", err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("Sending signals is not supported: %v", err)
+	}
+}
+
+// ignoreInterrupt ignores SIGINT for the rest of the test.
+// Catching the signal briefly when the test ends clears the ignored state, which signal.Reset leaves behind.
+func ignoreInterrupt(t *testing.T) {
+	t.Helper()
+	signal.Ignore(os.Interrupt)
+	t.Cleanup(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt)
+		signal.Stop(ch)
+	})
+}
+
+// TestRestoreOnSignalDefault tests that mode changes leave signals to a program's own handler by default.
+// This test catches SIGINT itself and checks that it arrives once and that the mode stays on.
+func TestRestoreOnSignalDefault(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	ch := make(chan os.Signal, 4)
+	signal.Notify(ch, os.Interrupt)
+	defer signal.Stop(ch)
+
+	restore, err := probe.EnableBracketedPaste(slave.Fd())
+	if err != nil {
+		t.Fatalf("EnableBracketedPaste failed: %v", err)
+	}
+	defer restore()
+
+	sendInterrupt(t)
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for SIGINT")
+	}
+	select {
+	case <-ch:
+		t.Errorf("Expected SIGINT to be delivered once")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if got := string(stop()); strings.Contains(got, "\x1b[?2004l") {
+		t.Errorf("Expected the mode to stay on, got %q", got)
+	}
+}
+
+// TestRestoreOnSignalIgnored tests that RestoreOnSignal does not catch a signal the process ignores,
+// which would stop it from being ignored.
+func TestRestoreOnSignalIgnored(t *testing.T) {
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+
+	probe.RestoreOnSignal(true)
+	defer probe.RestoreOnSignal(false)
+	ignoreInterrupt(t)
+
+	restore, err := probe.EnableBracketedPaste(slave.Fd())
+	if err != nil {
+		t.Fatalf("EnableBracketedPaste failed: %v", err)
+	}
+	defer restore()
+
+	sendInterrupt(t)
+	time.Sleep(100 * time.Millisecond)
+
+	if !signal.Ignored(os.Interrupt) {
+		t.Errorf("Expected SIGINT to stay ignored")
+	}
+	if got := string(stop()); strings.Contains(got, "\x1b[?2004l") {
+		t.Errorf("Expected the mode to stay on, got %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSize tests reading the window size of a pseudo-terminal and the error for a non-terminal.
+func TestSize(t *testing.T) {
+	master, slave := openPTY(t)
+	setWindowSize(t, master, 100, 30, 800, 480)
+
+	size, err := probe.Size(slave.Fd())
+	if err != nil {
+		t.Fatalf("Size failed: %v", err)
+	}
+	expected := probe.WindowSize{Columns: 100, Rows: 30, Width: 800, Height: 480}
+	if size != expected {
+		t.Errorf("Expected %+v, got %+v", expected, size)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	var notTerminal *probe.NotTerminalError
+	if _, err := probe.Size(w.Fd()); !errors.As(err, &notTerminal) {
+		t.Errorf("Expected NotTerminalError, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// TestSetTitle tests the sequences written to set and restore a title on different emulators.
+func TestSetTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		kind     probe.TitleKind
+		expected string
+	}{
+		{"xterm-stack", map[string]string{"TERM": "xterm-256color", "XTERM_VERSION": "XTerm(390)"}, probe.WindowTitle, "\x1b[22;2t\x1b]2;jobx\x1b\\\x1b[23;2t"},
+		{"apple-terminal", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "Apple_Terminal"}, probe.TitleAndIcon, "\x1b]0;jobx\x1b\\\x1b]0;\x1b\\"},
+		{"tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux-0/default,1,0", "XTERM_VERSION": "XTerm(390)"}, probe.IconName, "\x1b]1;jobx\x1b\\\x1b]1;\x1b\\"},
+		{"linux-console", map[string]string{"TERM": "linux"}, probe.WindowTitle, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, nil)
+
+			restore, err := probe.SetTitle(slave.Fd(), tt.kind, "job\x07x")
+			if err != nil {
+				t.Fatalf("SetTitle failed: %v", err)
+			}
+			if err := restore(); err != nil {
+				t.Errorf("Restoring the title failed: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if got := string(stop()); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestPushPopTitle tests that the title stack is only used where it is supported.
+func TestPushPopTitle(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, "\x1b[22;0t\x1b[23;0t"},
+		{"windows-terminal", map[string]string{"WT_SESSION": "1"}, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, tt.env)
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, nil)
+
+			if err := probe.PushTitle(slave.Fd(), probe.TitleAndIcon); err != nil {
+				t.Errorf("PushTitle failed: %v", err)
+			}
+			if err := probe.PopTitle(slave.Fd(), probe.TitleAndIcon); err != nil {
+				t.Errorf("PopTitle failed: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			if got := string(stop()); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSetTitleNonTerminal tests that nothing is written to a pipe.
+func TestSetTitleNonTerminal(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "xterm-256color", "XTERM_VERSION": "XTerm(390)"})
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	restore, err := probe.SetTitle(w.Fd(), probe.WindowTitle, "job")
+	if err != nil {
+		t.Fatalf("SetTitle failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Errorf("Restoring the title failed: %v", err)
+	}
+	if err := probe.PushTitle(w.Fd(), probe.WindowTitle); err != nil {
+		t.Errorf("PushTitle failed: %v", err)
+	}
+
+	w.Close()
+	buf := make([]byte, 64)
+	if n, _ := r.Read(buf); n != 0 {
+		t.Errorf("Expected nothing to be written to the pipe, got %q", buf[:n])
+	}
+}
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/droqsic/probe"
+)
+
+// setLocale sets the locale variables for the rest of the test, unsetting those that are empty.
+func setLocale(t *testing.T, all, ctype, lang string) {
+	t.Helper()
+	for name, value := range map[string]string{"LC_ALL": all, "LC_CTYPE": ctype, "LANG": lang} {
+		t.Setenv(name, value)
+		if value == "" {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// TestUnicodeSupportLocale tests the detection of UTF-8 codesets and the precedence of the locale variables.
+func TestUnicodeSupportLocale(t *testing.T) {
+	tests := []struct {
+		name             string
+		all, ctype, lang string
+		locale           string
+		utf8             bool
+	}{
+		{"lang", "", "", "en_US.UTF-8", "en_US.UTF-8", true},
+		{"lowercase", "", "", "C.utf8", "C.utf8", true},
+		{"bare codeset", "", "UTF-8", "", "UTF-8", true},
+		{"bare lowercase codeset", "", "utf8", "", "utf8", true},
+		{"no codeset", "", "", "en_US", "en_US", false},
+		{"modifier", "", "", "de_DE.utf-8@euro", "de_DE.utf-8@euro", true},
+		{"ctype over lang", "", "en_US.ISO-8859-1", "en_US.UTF-8", "en_US.ISO-8859-1", false},
+		{"all over ctype", "POSIX", "en_US.UTF-8", "", "POSIX", false},
+		{"unset", "", "", "", "C", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEmulator(t, map[string]string{"TERM": "xterm-256color"})
+			setLocale(t, tt.all, tt.ctype, tt.lang)
+
+			u, err := probe.UnicodeSupport(os.Stdout.Fd())
+			if err != nil {
+				t.Fatalf("UnicodeSupport failed: %v", err)
+			}
+			if u.Locale != tt.locale || u.UTF8 != tt.utf8 || u.LimitedGlyphs || u.Measured {
+				t.Errorf("Expected locale %q and UTF-8 %v, got %+v", tt.locale, tt.utf8, u)
+			}
+		})
+	}
+}
+
+// TestUnicodeSupportEastAsian tests the detection of Chinese, Japanese and Korean locales.
+func TestUnicodeSupportEastAsian(t *testing.T) {
+	tests := []struct {
+		lang      string
+		eastAsian bool
+	}{
+		{"ja_JP.UTF-8", true},
+		{"zh_TW.Big5", true},
+		{"ko", true},
+		{"en_US.UTF-8", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		setLocale(t, "", "", tt.lang)
+		u, err := probe.UnicodeSupport(os.Stdout.Fd())
+		if err != nil {
+			t.Fatalf("UnicodeSupport failed: %v", err)
+		}
+		if u.EastAsian != tt.eastAsian {
+			t.Errorf("Expected East Asian %v for %q, got %v", tt.eastAsian, tt.lang, u.EastAsian)
+		}
+	}
+}
+
+// TestUnicodeSupportLinuxConsole tests that the Linux console is reported as having limited glyphs.
+func TestUnicodeSupportLinuxConsole(t *testing.T) {
+	setEmulator(t, map[string]string{"TERM": "linux"})
+	setLocale(t, "", "", "en_US.UTF-8")
+
+	u, err := probe.UnicodeSupport(os.Stdout.Fd())
+	if err != nil {
+		t.Fatalf("UnicodeSupport failed: %v", err)
+	}
+	if !u.UTF8 || !u.LimitedGlyphs {
+		t.Errorf("Expected UTF-8 with limited glyphs, got %+v", u)
+	}
+}
+
+// TestUnicodeSupportMeasurement tests measuring the width of wide characters and emoji with cursor position reports.
+func TestUnicodeSupportMeasurement(t *testing.T) {
+	request := "\x1b7\x1b[6n中\x1b[6n\x1b8\U0001f600\x1b[6n\x1b8\x1b[K\x1b[c"
+	tests := []struct {
+		name     string
+		reply    string
+		measured bool
+		wide     bool
+		emoji    bool
+	}{
+		{"both wide", "\x1b[3;1R\x1b[3;3R\x1b[3;3R\x1b[?62c", true, true, true},
+		{"narrow emoji", "\x1b[3;5R\x1b[3;7R\x1b[3;6R\x1b[?62c", true, true, false},
+		{"no reports", "\x1b[?62c", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocale(t, "", "", "en_US.UTF-8")
+			master, slave := openPTY(t)
+			stop := fakeTerminal(t, master, map[string]string{request: tt.reply})
+			defer stop()
+
+			u, err := probe.UnicodeSupport(slave.Fd(), probe.WithWidthMeasurement(context.Background()))
+			if err != nil {
+				t.Fatalf("UnicodeSupport failed: %v", err)
+			}
+			if u.Measured != tt.measured || u.Wide != tt.wide || u.Emoji != tt.emoji {
+				t.Errorf("Expected measured %v, wide %v and emoji %v, got %+v", tt.measured, tt.wide, tt.emoji, u)
+			}
+		})
+	}
+}
+
+// TestUnicodeSupportMeasurementErrors tests that pipes skip the measurement and silent terminals return ErrNoReply.
+func TestUnicodeSupportMeasurementErrors(t *testing.T) {
+	setLocale(t, "", "", "en_US.UTF-8")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	u, err := probe.UnicodeSupport(w.Fd(), probe.WithWidthMeasurement(context.Background()))
+	if err != nil || u.Measured || !u.UTF8 {
+		t.Errorf("Expected an unmeasured UTF-8 result for a pipe, got %+v, %v", u, err)
+	}
+
+	master, slave := openPTY(t)
+	stop := fakeTerminal(t, master, nil)
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	u, err = probe.UnicodeSupport(slave.Fd(), probe.WithWidthMeasurement(ctx))
+	if !errors.Is(err, probe.ErrNoReply) || !u.UTF8 {
+		t.Errorf("Expected ErrNoReply with the locale result, got %+v, %v", u, err)
+	}
+}
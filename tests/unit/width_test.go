@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/droqsic/probe"
+	"github.com/droqsic/probe/width"
+)
+
+// TestWidthString tests the cell width of ASCII, CJK, combining marks, emoji sequences and escape sequences.
+func TestWidthString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"cjk", "日本語", 6},
+		{"fullwidth", "ＡＢ", 4},
+		{"hangul", "한국어", 6},
+		{"combining", "e\u0301te\u0301", 3},
+		{"emoji", "\U0001f600", 2},
+		{"skin tone", "\U0001f44d\U0001f3fd", 2},
+		{"zwj family", "\U0001f468‍\U0001f469‍\U0001f467", 2},
+		{"zwj text emoji", "\U0001f3f3\ufe0f\u200d\U0001f308", 2},
+		{"zwj letters", "a\u200db", 2},
+		{"zwj letter emoji", "a\u200d\U0001f600", 3},
+		{"zwj devanagari", "क्\u200dष", 2},
+		{"zwj arabic", "ل\u200dا", 2},
+		{"trailing zwj", "\U0001f600\u200d", 2},
+		{"flag", "\U0001f1ef\U0001f1f5", 2},
+		{"text heart", "❤", 1},
+		{"emoji heart", "❤️", 2},
+		{"controls", "a\tb\r\n", 2},
+		{"sgr", "\x1b[1;31mred\x1b[0m", 3},
+		{"hyperlink", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"charset", "\x1b(Bx", 1},
+		{"escape before multibyte", "\x1b日本", 4},
+		{"escape before control", "\x1b\tab", 2},
+		{"ambiguous", "αβ", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := width.String(tt.input); got != tt.expected {
+				t.Errorf("Expected width %d for %q, got %d", tt.expected, tt.input, got)
+			}
+		})
+	}
+}
+
+// TestWidthAmbiguous tests the ambiguous-width policy, set directly and derived from the locale.
+func TestWidthAmbiguous(t *testing.T) {
+	if got := width.New(width.WithAmbiguousWide(true)).String("α→β"); got != 6 {
+		t.Errorf("Expected ambiguous characters to be wide, got width %d", got)
+	}
+	if got := width.New(width.WithUnicode(probe.Unicode{EastAsian: true})).Rune('§'); got != 2 {
+		t.Errorf("Expected § to be wide in an East Asian locale, got width %d", got)
+	}
+	if got := width.New(width.WithUnicode(probe.Unicode{})).Rune('§'); got != 1 {
+		t.Errorf("Expected § to be narrow elsewhere, got width %d", got)
+	}
+	if got := width.New(width.WithAmbiguousWide(true)).String("日本"); got != 4 {
+		t.Errorf("Expected wide characters to stay two cells, got width %d", got)
+	}
+}
+
+// TestWidthTruncate tests truncation at cluster boundaries, with tails and with escape sequences kept intact.
+func TestWidthTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		tail     string
+		expected string
+	}{
+		{"fits", "hello", 5, "…", "hello"},
+		{"ascii", "hello world", 8, "…", "hello w…"},
+		{"wide boundary", "日本語テキスト", 7, "…", "日本語…"},
+		{"no tail", "日本語", 3, "", "日"},
+		{"zwj kept whole", "ab\U0001f468‍\U0001f469cd", 4, "", "ab\U0001f468‍\U0001f469"},
+		{"escape before multibyte", "\x1b日本語", 4, "", "\x1b日本"},
+		{"styles closed", "\x1b[31mhello world\x1b[0m", 6, "…", "\x1b[31mhello…\x1b[0m"},
+		{"wide tail", "hello", 2, "...", ".."},
+		{"zero", "hello", 0, "…", ""},
+		{"zero no tail", "hello", 0, "", ""},
+		{"negative", "abcdef", -1, "…", ""},
+		{"negative no tail", "abcdef", -1, "", ""},
+		{"empty negative", "", -1, "…", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := width.Truncate(tt.input, tt.width, tt.tail)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Expected valid UTF-8, got %q", got)
+			}
+			if w := width.String(got); w > max(tt.width, 0) {
+				t.Errorf("Expected at most %d cells, got %d", max(tt.width, 0), w)
+			}
+		})
+	}
+}
+
+// TestWidthPadding tests padding to a column width with wide characters and escape sequences.
+func TestWidthPadding(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(string, int) string
+		input    string
+		expected string
+	}{
+		{"right", width.PadRight, "日本", "日本 "},
+		{"left", width.PadLeft, "日本", " 日本"},
+		{"center", width.Center, "ab", " ab  "},
+		{"styled", width.PadRight, "\x1b[1mab\x1b[0m", "\x1b[1mab\x1b[0m   "},
+		{"too wide", width.PadRight, "abcdefg", "abcdefg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fn(tt.input, 5)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/droqsic/probe/width"
+)
+
+// TestWrap tests wrapping on word boundaries, splitting long words and keeping paragraphs and leading spaces.
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		expected string
+	}{
+		{"fits", "hello world", 20, "hello world"},
+		{"words", "the quick brown fox jumps", 10, "the quick\nbrown fox\njumps"},
+		{"exact", "abc def", 7, "abc def"},
+		{"long word", "abcdefghij xy", 4, "abcd\nefgh\nij\nxy"},
+		{"wide", "日本語 テキスト", 6, "日本語\nテキス\nト"},
+		{"paragraphs", "one two\nthree four", 5, "one\ntwo\nthree\nfour"},
+		{"leading spaces", "  - item one", 8, "  - item\none"},
+		{"leading spaces dropped", "  indented long paragraph here", 8, "indented\nlong\nparagrap\nh here"},
+		{"only spaces", "   ", 8, ""},
+		{"zero width", "ab c", 0, "a\nb\nc"},
+		{"negative width", "ab", -1, "a\nb"},
+		{"extra spaces", "a  b", 10, "a  b"},
+		{"trailing spaces", "ab   ", 4, "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := width.Wrap(tt.input, tt.width)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			for _, line := range strings.Split(got, "\n") {
+				if w := width.String(line); w > max(tt.width, 1) {
+					t.Errorf("Line %q is %d cells wide, more than %d", line, w, max(tt.width, 1))
+				}
+			}
+		})
+	}
+}
+
+// TestWrapIndent tests hanging and block indents, which count towards the width.
+func TestWrapIndent(t *testing.T) {
+	got := width.Wrap("show this help message and exit", 20, width.WithIndent("  -h  ", "      "))
+	expected := "  -h  show this help\n      message and\n      exit"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	got = width.Wrap("one two\nthree", 7, width.WithIndent("> ", "> "))
+	expected = "> one\n> two\n> three"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+// TestWrapStyles tests that styles and hyperlinks open at a line break are closed and opened again.
+func TestWrapStyles(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"sgr", "\x1b[1m\x1b[31mbold red\x1b[0m text", "\x1b[1m\x1b[31mbold\x1b[0m\n\x1b[1;31mred\x1b[0m\ntext"},
+		{"reset with params", "\x1b[0;32mgreen words", "\x1b[0;32mgreen\x1b[0m\n\x1b[32mwords"},
+		{"padded reset", "\x1b[1mab\x1b[00m cd efgh", "\x1b[1mab\x1b[00m cd\nefgh"},
+		{"partial reset", "\x1b[1;31mab\x1b[39m cd", "\x1b[1;31mab\x1b[39m cd"},
+		{"partial reset at break", "\x1b[1;31mabc\x1b[39m def", "\x1b[1;31mabc\x1b[39m\x1b[0m\n\x1b[1mdef"},
+		{"bold and faint reset", "\x1b[1;2;3mab\x1b[22m cd efg", "\x1b[1;2;3mab\x1b[22m cd\x1b[0m\n\x1b[3mefg"},
+		{"256 colors", "\x1b[38;5;196;48;2;1;2;3mab cd efg", "\x1b[38;5;196;48;2;1;2;3mab cd\x1b[0m\n\x1b[38;5;196;48;2;1;2;3mefg"},
+		{"colon colors", "\x1b[4:3;58:2::1:2:3mab cd efg", "\x1b[4:3;58:2::1:2:3mab cd\x1b[0m\n\x1b[4:3;58:2::1:2:3mefg"},
+		{"private marker", "\x1b[>4;1mab cd efg", "\x1b[>4;1mab cd\nefg"},
+		{"closed before break", "\x1b[1mab\x1b[0m cd", "\x1b[1mab\x1b[0m cd"},
+		{"hyperlink", "\x1b]8;;https://x.y\x1b\\link text\x1b]8;;\x1b\\", "\x1b]8;;https://x.y\x1b\\link\x1b]8;;\x1b\\\n\x1b]8;;https://x.y\x1b\\text\x1b]8;;\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := width.Wrap(tt.input, 5)
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestWrapStylesBounded tests that styles turned off before a line break are not opened again after it,
+// so that the output stays proportional to the input however many lines it spans.
+func TestWrapStylesBounded(t *testing.T) {
+	input := strings.Repeat("\x1b[31mword\x1b[39m \x1b[1;4mbold\x1b[22;24m ", 1000)
+	got := width.Wrap(input, 20)
+	if len(got) > 2*len(input) {
+		t.Errorf("Expected at most %d bytes of output, got %d", 2*len(input), len(got))
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) > 200 {
+			t.Fatalf("Expected short lines, got %d bytes: %q", len(line), line[:200])
+		}
+	}
+}
+
+// TestColumns tests the fallback from the terminal width to COLUMNS and to the default.
+func TestColumns(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	t.Setenv("COLUMNS", "42")
+	if got := width.Columns(w.Fd()); got != 42 {
+		t.Errorf("Expected 42 columns from COLUMNS, got %d", got)
+	}
+	t.Setenv("COLUMNS", "wide")
+	if got := width.Columns(w.Fd()); got != width.DefaultColumns {
+		t.Errorf("Expected the default of %d columns, got %d", width.DefaultColumns, got)
+	}
+	if got := width.WrapFor(w.Fd(), strings.Repeat("word ", 20)); strings.Count(got, "\n") != 1 {
+		t.Errorf("Expected 100 cells to wrap once at %d columns, got %q", width.DefaultColumns, got)
+	}
+
+	master, slave := openPTY(t)
+	setWindowSize(t, master, 132, 43, 0, 0)
+	if got := width.Columns(slave.Fd()); got != 132 {
+		t.Errorf("Expected 132 columns from the terminal, got %d", got)
+	}
+}
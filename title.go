@@ -0,0 +1,113 @@
+package probe
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/droqsic/probe/platform"
+)
+
+// TitleKind selects which of the terminal's titles a change applies to.
+// Its values are the OSC and XTWINOPS parameters that select them.
+type TitleKind int
+
+// These constants enumerate the titles.
+const (
+	TitleAndIcon TitleKind = 0 // Both the window title and the icon name (OSC 0)
+	IconName     TitleKind = 1 // The icon name, shown by some window managers for minimized windows and by tabs (OSC 1)
+	WindowTitle  TitleKind = 2 // The window title (OSC 2)
+)
+
+// SetTitle sets a title of the terminal and returns a function that restores the previous one, which should be deferred.
+// Where the terminal supports the XTWINOPS title stack, the previous title is pushed first and popped by restore;
+// elsewhere restore sets an empty title, which terminals treat as going back to their default.
+// With RestoreOnSignal, the previous title is also restored if the process receives a termination signal first.
+// Control characters are removed from the title so that it cannot end the sequence early.
+// Nothing is written when the file descriptor is not a terminal or the terminal is known not to support titles.
+func SetTitle(fd uintptr, kind TitleKind, title string) (restore func() error, err error) {
+	if !supportsTitle() {
+		return func() error { return nil }, nil
+	}
+	apply, revert := titleChange(fd, kind, title)
+	return enable(fd, apply, revert)
+}
+
+// PushTitle saves a title of the terminal on its title stack (XTWINOPS 22).
+// Nothing is written when the file descriptor is not a terminal or the terminal is not known to have a title stack.
+func PushTitle(fd uintptr, kind TitleKind) error {
+	if !IsTerminal(fd) || !supportsTitleStack() {
+		return nil
+	}
+	return writeTitleStack(fd, 22, kind)
+}
+
+// PopTitle restores a title of the terminal from its title stack (XTWINOPS 23).
+// Nothing is written when the file descriptor is not a terminal or the terminal is not known to have a title stack.
+func PopTitle(fd uintptr, kind TitleKind) error {
+	if !IsTerminal(fd) || !supportsTitleStack() {
+		return nil
+	}
+	return writeTitleStack(fd, 23, kind)
+}
+
+// titleChange returns functions that set a title and restore the previous one, for SetTitle and Session.SetTitle.
+func titleChange(fd uintptr, kind TitleKind, title string) (apply, revert func() error) {
+	if supportsTitleStack() {
+		apply = func() error {
+			if err := writeTitleStack(fd, 22, kind); err != nil {
+				return err
+			}
+			return writeTitle(fd, kind, title)
+		}
+		revert = func() error { return writeTitleStack(fd, 23, kind) }
+		return apply, revert
+	}
+
+	apply = func() error { return writeTitle(fd, kind, title) }
+	revert = func() error { return writeTitle(fd, kind, "") }
+	return apply, revert
+}
+
+// supportsTitle reports whether the terminal emulator is expected to understand title sequences.
+// Consoles and editors that would show them as garbage or ignore them are excluded.
+func supportsTitle() bool {
+	switch detectEmulator().name {
+	case emulatorLinux, emulatorDumb, emulatorEmacs:
+		return false
+	}
+	return true
+}
+
+// supportsTitleStack reports whether the terminal emulator is known to implement the XTWINOPS title stack.
+// Multiplexers do not pass the operations through, so the stack is never used inside one.
+func supportsTitleStack() bool {
+	e := detectEmulator()
+	if e.multiplexer != "" {
+		return false
+	}
+	switch e.name {
+	case emulatorXterm, emulatorVTE, emulatorKitty, emulatorFoot, emulatorWezTerm, emulatorITerm2, emulatorMintty, emulatorAlacritty:
+		return true
+	}
+	return false
+}
+
+// writeTitle writes an OSC sequence that sets a title, removing control characters from it.
+func writeTitle(fd uintptr, kind TitleKind, title string) error {
+	seq := append([]byte("\x1b]"), strconv.Itoa(int(kind))...)
+	seq = append(seq, ';')
+	seq = append(seq, strings.Map(dropControl, title)...)
+	seq = append(seq, "\x1b\\"...)
+
+	_, err := platform.Write(fd, seq)
+	return err
+}
+
+// writeTitleStack writes an XTWINOPS title stack operation, "CSI op ; kind t".
+func writeTitleStack(fd uintptr, op int, kind TitleKind) error {
+	seq := append([]byte("\x1b["), strconv.Itoa(op)...)
+	seq = append(seq, ';')
+	seq = append(seq, strconv.Itoa(int(kind))...)
+	_, err := platform.Write(fd, append(seq, 't'))
+	return err
+}
@@ -0,0 +1,117 @@
+package probe
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Unicode describes how well the terminal and the locale handle text beyond ASCII.
+type Unicode struct {
+	UTF8          bool   `json:"utf8"`           // Whether the locale's codeset is UTF-8
+	Locale        string `json:"locale"`         // Locale in effect for character handling, from LC_ALL, LC_CTYPE or LANG
+	EastAsian     bool   `json:"east_asian"`     // Whether the locale is Chinese, Japanese or Korean, where ambiguous-width characters are drawn wide
+	LimitedGlyphs bool   `json:"limited_glyphs"` // Whether the terminal shows only a small set of glyphs, as the Linux console does
+	Measured      bool   `json:"measured"`       // Whether the fields below were measured with WithWidthMeasurement
+	Wide          bool   `json:"wide"`           // Whether East Asian wide characters occupy two cells
+	Emoji         bool   `json:"emoji"`          // Whether emoji occupy two cells
+}
+
+// UnicodeOption configures UnicodeSupport.
+type UnicodeOption func(*unicodeOptions)
+
+// unicodeOptions holds the settings applied by UnicodeOption functions.
+type unicodeOptions struct {
+	measure bool            // Whether to measure the width of wide characters and emoji
+	ctx     context.Context // Bounds the measurement
+}
+
+// WithWidthMeasurement makes UnicodeSupport write a wide character and an emoji to the terminal and ask
+// for the cursor position after each, to learn how many cells they really occupy. The cursor is restored
+// and the rest of the line erased afterwards, so it is best done before anything is drawn on that line.
+// If ctx has no deadline, DefaultQueryTimeout applies.
+func WithWidthMeasurement(ctx context.Context) UnicodeOption {
+	return func(o *unicodeOptions) {
+		o.measure = true
+		o.ctx = ctx
+	}
+}
+
+// widthProbe saves the cursor and reports its position before and after a wide character and an emoji,
+// returning to the saved position before each, then erases what it drew. The DA1 sentinel is appended by query.
+const widthProbe = "\x1b7\x1b[6n" + "中\x1b[6n\x1b8" + "\U0001f600\x1b[6n\x1b8\x1b[K"
+
+// UnicodeSupport reports whether text beyond ASCII, such as box-drawing characters and emoji, can be written
+// to the terminal on fd, so that programs can fall back to ASCII otherwise. It checks the codeset of the
+// locale from LC_ALL, LC_CTYPE and LANG, in the order of precedence POSIX gives them, and TERM=linux,
+// whose console font holds only a few hundred glyphs. With WithWidthMeasurement it also measures the width
+// of wide characters and emoji; measurement is skipped when fd is not a terminal, and its errors are
+// returned along with the result of the other checks.
+func UnicodeSupport(fd uintptr, opts ...UnicodeOption) (Unicode, error) {
+	o := unicodeOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	u := Unicode{
+		Locale:        locale(),
+		LimitedGlyphs: os.Getenv("TERM") == "linux",
+	}
+	u.UTF8 = isUTF8Locale(u.Locale)
+	u.EastAsian = isEastAsianLocale(u.Locale)
+
+	if !o.measure || !IsTerminal(fd) {
+		return u, nil
+	}
+	replies, _, err := query(o.ctx, fd, widthProbe)
+	if err != nil {
+		return u, err
+	}
+
+	// Cursor position reports, "CSI row ; column R".
+	var columns []int
+	for _, reply := range replies {
+		if p := reply.params(); reply.intro == '[' && reply.final == 'R' && len(p) == 2 && p[1] > 0 {
+			columns = append(columns, p[1])
+		}
+	}
+	if len(columns) == 3 {
+		u.Measured = true
+		u.Wide = columns[1]-columns[0] == 2
+		u.Emoji = columns[2]-columns[0] == 2
+	}
+	return u, nil
+}
+
+// locale returns the locale that applies to character handling, or "C" if none is set.
+func locale() string {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "C"
+}
+
+// isUTF8Locale reports whether a locale name such as "en_US.UTF-8" or "C.utf8@euro" names the UTF-8 codeset.
+// A bare codeset, such as the "UTF-8" that macOS Terminal sets in LC_CTYPE, counts as well.
+func isUTF8Locale(locale string) bool {
+	codeset := locale
+	if _, after, ok := strings.Cut(locale, "."); ok {
+		codeset = after
+	}
+	codeset, _, _ = strings.Cut(codeset, "@")
+	codeset = strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(codeset))
+	return codeset == "utf8"
+}
+
+// isEastAsianLocale reports whether a locale name such as "ja_JP.UTF-8" names a Chinese, Japanese or Korean locale.
+func isEastAsianLocale(locale string) bool {
+	language, _, _ := strings.Cut(locale, "_")
+	language, _, _ = strings.Cut(language, ".")
+	switch strings.ToLower(language) {
+	case "zh", "ja", "ko":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package width
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Attributes an SGR sequence can set, each of which is turned off independently of the others.
+const (
+	slotBold = iota
+	slotFaint
+	slotItalic
+	slotUnderline
+	slotBlink
+	slotInverse
+	slotHidden
+	slotStrike
+	slotOverline
+	slotForeground
+	slotBackground
+	slotUnderlineColor
+	slotCount
+)
+
+// sgrState is the set of SGR attributes in effect. Each slot holds the parameters that set it,
+// such as "1" or "38;5;196", or is empty when the attribute is off.
+type sgrState [slotCount]string
+
+// apply updates the state with the parameters of an SGR sequence, the text between "CSI" and "m".
+// Sequences with a private marker, such as "CSI > 4 ; 1 m", are not SGR and are ignored.
+func (s *sgrState) apply(params string) {
+	if params != "" && strings.ContainsRune("<=>?", rune(params[0])) {
+		return
+	}
+
+	fields := strings.Split(params, ";")
+	for i := 0; i < len(fields); i++ {
+		p := fields[i]
+		base, sub, _ := strings.Cut(p, ":")
+		n := 0
+		if base != "" {
+			var err error
+			if n, err = strconv.Atoi(base); err != nil {
+				continue
+			}
+		}
+
+		switch {
+		case n == 0:
+			*s = sgrState{}
+		case n == 1:
+			s[slotBold] = p
+		case n == 2:
+			s[slotFaint] = p
+		case n == 3:
+			s[slotItalic] = p
+		case n == 4 && sub == "0", n == 24:
+			s[slotUnderline] = ""
+		case n == 4, n == 21:
+			s[slotUnderline] = p
+		case n == 5, n == 6:
+			s[slotBlink] = p
+		case n == 7:
+			s[slotInverse] = p
+		case n == 8:
+			s[slotHidden] = p
+		case n == 9:
+			s[slotStrike] = p
+		case n == 22:
+			s[slotBold], s[slotFaint] = "", ""
+		case n == 23:
+			s[slotItalic] = ""
+		case n == 25:
+			s[slotBlink] = ""
+		case n == 27:
+			s[slotInverse] = ""
+		case n == 28:
+			s[slotHidden] = ""
+		case n == 29:
+			s[slotStrike] = ""
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			s[slotForeground] = p
+		case n == 39:
+			s[slotForeground] = ""
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			s[slotBackground] = p
+		case n == 49:
+			s[slotBackground] = ""
+		case n == 53:
+			s[slotOverline] = p
+		case n == 55:
+			s[slotOverline] = ""
+		case n == 59:
+			s[slotUnderlineColor] = ""
+		case n == 38, n == 48, n == 58:
+			// Extended colors are "38:5:n" in one parameter, or "38;5;n" and "38;2;r;g;b" across several.
+			value := p
+			if !strings.Contains(p, ":") && i+1 < len(fields) {
+				count := map[string]int{"5": 2, "2": 4}[fields[i+1]]
+				end := min(i+1+count, len(fields))
+				value = strings.Join(fields[i:end], ";")
+				i = end - 1
+			}
+			slot := map[int]int{38: slotForeground, 48: slotBackground, 58: slotUnderlineColor}[n]
+			s[slot] = value
+		}
+	}
+}
+
+// String returns a single SGR sequence that sets the attributes in effect, or an empty string if none are.
+func (s *sgrState) String() string {
+	var params []string
+	for _, p := range s {
+		if p != "" {
+			params = append(params, p)
+		}
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
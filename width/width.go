@@ -0,0 +1,287 @@
+// Package width measures how many terminal cells text occupies, for aligning columns and truncating lines.
+//
+// Widths follow the East Asian Width property of Unicode and are counted per grapheme cluster,
+// so that combining marks, emoji with skin tone modifiers, emoji ZWJ sequences, flags and the emoji
+// variation selector are measured the way terminals draw them. ANSI escape sequences, such as
+// colors and hyperlinks, occupy no cells and are kept intact by Truncate.
+package width
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/droqsic/probe"
+)
+
+// Code points with special roles in grapheme clusters.
+const (
+	zeroWidthJoiner   = 0x200D  // Joins emoji into a single glyph, as in a family emoji
+	emojiPresentation = 0xFE0F  // Variation selector 16, which asks for the colorful two-cell emoji glyph
+	regionalFirst     = 0x1F1E6 // First regional indicator symbol; pairs of them form flags
+	regionalLast      = 0x1F1FF // Last regional indicator symbol
+)
+
+// table is a sorted list of inclusive code point ranges.
+type table [][2]rune
+
+// contains reports whether r is in one of the ranges.
+func (t table) contains(r rune) bool {
+	i := sort.Search(len(t), func(i int) bool { return t[i][1] >= r })
+	return i < len(t) && t[i][0] <= r
+}
+
+// Option configures a Measurer.
+type Option func(*Measurer)
+
+// WithAmbiguousWide counts the East Asian Ambiguous characters, such as Greek and Cyrillic letters,
+// box-drawing characters and many symbols, as two cells instead of one, as terminals configured
+// for Chinese, Japanese or Korean text draw them.
+func WithAmbiguousWide(wide bool) Option {
+	return func(m *Measurer) {
+		m.ambiguousWide = wide
+	}
+}
+
+// WithUnicode derives the ambiguous-width policy from the result of probe.UnicodeSupport:
+// ambiguous characters are counted as two cells in Chinese, Japanese and Korean locales.
+func WithUnicode(u probe.Unicode) Option {
+	return WithAmbiguousWide(u.EastAsian)
+}
+
+// Measurer measures and formats text according to an ambiguous-width policy.
+// It is safe for concurrent use.
+type Measurer struct {
+	ambiguousWide bool // Whether East Asian Ambiguous characters occupy two cells
+}
+
+// New returns a Measurer configured by the options.
+// Without options ambiguous characters occupy one cell, as in most Western locales.
+func New(opts ...Option) *Measurer {
+	m := &Measurer{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// defaultMeasurer is used by the package-level functions.
+var defaultMeasurer = New()
+
+// Rune returns the number of cells a code point occupies on its own:
+// zero for control characters and combining marks, two for wide characters and one otherwise.
+func (m *Measurer) Rune(r rune) int {
+	switch {
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return 0
+	case r < 0x300:
+		// Latin-1 has no wide or zero-width characters, only ambiguous ones.
+		if m.ambiguousWide && ambiguous.contains(r) {
+			return 2
+		}
+		return 1
+	case zero.contains(r):
+		return 0
+	case wide.contains(r):
+		return 2
+	case m.ambiguousWide && ambiguous.contains(r):
+		return 2
+	}
+	return 1
+}
+
+// String returns the number of cells s occupies, ignoring ANSI escape sequences.
+// A string containing newlines is measured as if it were on one line.
+func (m *Measurer) String(s string) int {
+	total := 0
+	for len(s) > 0 {
+		n, w, _ := m.next(s)
+		total += w
+		s = s[n:]
+	}
+	return total
+}
+
+// Truncate shortens s to at most w cells, ending it with tail, such as "…", when anything is cut.
+// Grapheme clusters are never split, so the result can be a cell shorter when a wide character
+// does not fit. Escape sequences after the cut are kept, so that styles and hyperlinks opened
+// before it are still closed. If tail is wider than w, it is truncated itself.
+// A width that is not positive leaves nothing, so the result is empty.
+func (m *Measurer) Truncate(s string, w int, tail string) string {
+	if m.String(s) <= w {
+		return s
+	}
+	if w <= 0 {
+		return ""
+	}
+	if m.String(tail) > w {
+		tail = m.Truncate(tail, w, "")
+	}
+	limit := w - m.String(tail)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	width, cut := 0, false
+	for len(s) > 0 {
+		n, cw, escape := m.next(s)
+		switch {
+		case escape:
+			b.WriteString(s[:n])
+		case cut:
+		case width+cw > limit:
+			cut = true
+			b.WriteString(tail)
+		default:
+			b.WriteString(s[:n])
+			width += cw
+		}
+		s = s[n:]
+	}
+	return b.String()
+}
+
+// PadRight appends spaces to s until it occupies w cells, aligning it to the left of a column.
+// Strings that already occupy w cells or more are returned unchanged.
+func (m *Measurer) PadRight(s string, w int) string {
+	return s + spaces(w-m.String(s))
+}
+
+// PadLeft prepends spaces to s until it occupies w cells, aligning it to the right of a column.
+func (m *Measurer) PadLeft(s string, w int) string {
+	return spaces(w-m.String(s)) + s
+}
+
+// Center surrounds s with spaces until it occupies w cells, with the extra space on the right when uneven.
+func (m *Measurer) Center(s string, w int) string {
+	pad := w - m.String(s)
+	if pad <= 0 {
+		return s
+	}
+	return spaces(pad/2) + s + spaces(pad-pad/2)
+}
+
+// next returns the length in bytes and the width of the escape sequence or grapheme cluster at the start of s,
+// and whether it is an escape sequence.
+func (m *Measurer) next(s string) (n, w int, escape bool) {
+	if s[0] == 0x1b {
+		return escapeLength(s), 0, true
+	}
+
+	r, n := utf8.DecodeRuneInString(s)
+	w = m.Rune(r)
+	switch {
+	case r == '\r' && n < len(s) && s[n] == '\n':
+		return n + 1, 0, false
+	case r < 0x20 || r >= 0x7f && r < 0xa0:
+		return n, 0, false
+	}
+
+	regional := r >= regionalFirst && r <= regionalLast
+	emoji := pictographic.contains(r)
+	for n < len(s) {
+		next, size := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case next == zeroWidthJoiner:
+			// A joiner between two emoji makes them a single two-cell glyph, as in a family emoji.
+			// Elsewhere, such as between Indic or Arabic letters, it only changes their shape,
+			// so the code point after it starts a cluster of its own.
+			n += size
+			if joined, size := utf8.DecodeRuneInString(s[n:]); emoji && pictographic.contains(joined) {
+				n += size
+				w = 2
+			}
+		case next == emojiPresentation:
+			n += size
+			if w == 1 {
+				w = 2
+			}
+		case extend.contains(next):
+			n += size
+		case regional && next >= regionalFirst && next <= regionalLast:
+			// Regional indicators pair up into a flag, which is drawn as a two-cell emoji.
+			n += size
+			regional, w = false, 2
+		default:
+			return n, w, false
+		}
+	}
+	return n, w, false
+}
+
+// escapeLength returns the length in bytes of the escape sequence at the start of s.
+// An unterminated sequence extends to the end of s.
+func escapeLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+
+	switch s[1] {
+	case '[':
+		// CSI: parameters and intermediates up to a final byte.
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']', 'P', '_', '^', 'X':
+		// OSC, DCS, APC, PM and SOS: a string ended by BEL or ST.
+		for i := 2; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		// Other escapes are followed by intermediate bytes, such as "(" when designating a character set, and a final byte.
+		// Anything else, such as the first byte of a multibyte character, is not part of the escape.
+		i := 1
+		for i < len(s) && s[i] >= 0x20 && s[i] <= 0x2f {
+			i++
+		}
+		if i < len(s) && s[i] >= 0x30 && s[i] <= 0x7e {
+			i++
+		}
+		return i
+	}
+	return len(s)
+}
+
+// spaces returns n spaces, or an empty string if n is not positive.
+func spaces(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
+
+// Rune returns the number of cells a code point occupies, counting ambiguous characters as one cell.
+func Rune(r rune) int {
+	return defaultMeasurer.Rune(r)
+}
+
+// String returns the number of cells s occupies, counting ambiguous characters as one cell.
+func String(s string) int {
+	return defaultMeasurer.String(s)
+}
+
+// Truncate shortens s to at most w cells as Measurer.Truncate does, counting ambiguous characters as one cell.
+func Truncate(s string, w int, tail string) string {
+	return defaultMeasurer.Truncate(s, w, tail)
+}
+
+// PadRight appends spaces to s until it occupies w cells, counting ambiguous characters as one cell.
+func PadRight(s string, w int) string {
+	return defaultMeasurer.PadRight(s, w)
+}
+
+// PadLeft prepends spaces to s until it occupies w cells, counting ambiguous characters as one cell.
+func PadLeft(s string, w int) string {
+	return defaultMeasurer.PadLeft(s, w)
+}
+
+// Center surrounds s with spaces until it occupies w cells, counting ambiguous characters as one cell.
+func Center(s string, w int) string {
+	return defaultMeasurer.Center(s, w)
+}
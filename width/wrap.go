@@ -0,0 +1,216 @@
+package width
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/droqsic/probe"
+)
+
+// DefaultColumns is the width Columns falls back to when neither the terminal nor COLUMNS gives one.
+const DefaultColumns = 80
+
+// Sequences that end the styles and the hyperlink open at the end of a wrapped line.
+const (
+	resetStyle   = "\x1b[0m"
+	endHyperlink = "\x1b]8;;\x1b\\"
+)
+
+// Columns returns the number of columns text written to fd can use: the width of the terminal window,
+// or the COLUMNS environment variable when fd is not a terminal, or DefaultColumns if that is not set either.
+func Columns(fd uintptr) int {
+	if size, err := probe.Size(fd); err == nil && size.Columns > 0 {
+		return size.Columns
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultColumns
+}
+
+// WrapOption configures Wrap.
+type WrapOption func(*wrapOptions)
+
+// wrapOptions holds the settings applied by WrapOption functions.
+type wrapOptions struct {
+	first string // Written at the start of the first line of each paragraph
+	rest  string // Written at the start of the lines that wrapping adds
+}
+
+// WithIndent writes first at the start of each paragraph and rest at the start of each line added by wrapping.
+// A longer rest gives a hanging indent, as in WithIndent("  -h  ", "        "), and equal ones indent a block.
+// Both count towards the width.
+func WithIndent(first, rest string) WrapOption {
+	return func(o *wrapOptions) {
+		o.first, o.rest = first, rest
+	}
+}
+
+// Wrap breaks s into lines of at most w cells, between words where possible.
+// Existing newlines start new paragraphs and spaces at the start of a paragraph are kept
+// if the first word fits after them; words wider than a line are split between grapheme clusters.
+// SGR styles and OSC 8 hyperlinks that are open at a line break are closed before it and opened
+// again after the indent, so that every line can be printed on its own; the styles are opened again
+// as a single sequence that sets only the attributes still in effect.
+// A width below one is treated as one, which puts every grapheme cluster on its own line.
+func (m *Measurer) Wrap(s string, w int, opts ...WrapOption) string {
+	w = max(w, 1)
+	o := wrapOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l := lineWriter{m: m, width: w, options: o}
+	l.b.Grow(len(s))
+	for i, paragraph := range strings.Split(s, "\n") {
+		if i > 0 {
+			l.close()
+			l.b.WriteByte('\n')
+		}
+		l.start(o.first)
+		l.paragraph(paragraph)
+	}
+	return l.b.String()
+}
+
+// WrapFor wraps s to the width Columns returns for fd, as Wrap does.
+func (m *Measurer) WrapFor(fd uintptr, s string, opts ...WrapOption) string {
+	return m.Wrap(s, Columns(fd), opts...)
+}
+
+// lineWriter accumulates wrapped lines and tracks the styles in effect at the end of the output.
+type lineWriter struct {
+	m       *Measurer
+	width   int
+	options wrapOptions
+	b       strings.Builder
+
+	column    int      // Cells used on the current line
+	lineStart int      // Cells used by the indent of the current line
+	style     sgrState // SGR attributes in effect
+	link      string   // OSC 8 sequence of the open hyperlink, or empty
+}
+
+// paragraph writes one line of the input, breaking it between words.
+func (l *lineWriter) paragraph(s string) {
+	// Leading spaces are part of the paragraph, such as the indent of a list item,
+	// and are kept when the first word fits after them; otherwise the first line would be blank.
+	trimmed := strings.TrimLeft(s, " ")
+	leading := len(s) - len(trimmed)
+	s = trimmed
+
+	for len(s) > 0 {
+		// Count the spaces before the next word; escape sequences among them move into the word.
+		spaces, visible := 0, false
+		var word strings.Builder
+		for len(s) > 0 {
+			n, _, escape := l.m.next(s)
+			if s[0] == ' ' {
+				if visible {
+					break
+				}
+				spaces++
+			} else {
+				word.WriteString(s[:n])
+				visible = visible || !escape
+			}
+			s = s[n:]
+		}
+		if leading > 0 && l.column+leading+l.m.String(word.String()) <= l.width {
+			l.b.WriteString(strings.Repeat(" ", leading))
+			l.column += leading
+		}
+		leading = 0
+		l.word(spaces, word.String())
+	}
+}
+
+// word writes a word preceded by spaces, starting a new line first if it does not fit.
+// The spaces are dropped at the start of a new line and after the last word.
+func (l *lineWriter) word(spaces int, word string) {
+	w := l.m.String(word)
+	if w == 0 {
+		// Escape sequences with no text, such as a reset after the last word, need no room.
+		l.write(word, false)
+		return
+	}
+
+	switch {
+	case l.column == l.lineStart:
+	case l.column+spaces+w > l.width:
+		l.newLine()
+	default:
+		l.b.WriteString(strings.Repeat(" ", spaces))
+		l.column += spaces
+	}
+	l.write(word, l.column+w > l.width)
+}
+
+// write writes s, tracking the escape sequences in it. If split is set, a new line is started
+// before each grapheme cluster that would not fit, unless the line is still empty.
+func (l *lineWriter) write(s string, split bool) {
+	for len(s) > 0 {
+		n, w, escape := l.m.next(s)
+		if escape {
+			l.track(s[:n])
+		} else if split && l.column > l.lineStart && l.column+w > l.width {
+			l.newLine()
+		}
+		l.b.WriteString(s[:n])
+		l.column += w
+		s = s[n:]
+	}
+}
+
+// track updates the styles and hyperlink in effect after the escape sequence seq.
+func (l *lineWriter) track(seq string) {
+	switch {
+	case strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m"):
+		l.style.apply(seq[2 : len(seq)-1])
+	case strings.HasPrefix(seq, "\x1b]8;"):
+		// "OSC 8 ; params ; url ST" opens a link, and an empty url closes it.
+		body := strings.TrimSuffix(strings.TrimSuffix(seq[4:], "\x1b\\"), "\a")
+		if _, url, _ := strings.Cut(body, ";"); url != "" {
+			l.link = seq
+		} else {
+			l.link = ""
+		}
+	}
+}
+
+// newLine ends the current line and starts one added by wrapping.
+func (l *lineWriter) newLine() {
+	l.close()
+	l.b.WriteByte('\n')
+	l.start(l.options.rest)
+}
+
+// close ends the styles and hyperlink in effect before a line break.
+func (l *lineWriter) close() {
+	if l.link != "" {
+		l.b.WriteString(endHyperlink)
+	}
+	if l.style != (sgrState{}) {
+		l.b.WriteString(resetStyle)
+	}
+}
+
+// start writes the indent of a new line and opens the styles and hyperlink in effect again.
+func (l *lineWriter) start(indent string) {
+	l.b.WriteString(indent)
+	l.column = l.m.String(indent)
+	l.lineStart = l.column
+	l.b.WriteString(l.style.String())
+	l.b.WriteString(l.link)
+}
+
+// Wrap breaks s into lines of at most w cells as Measurer.Wrap does, counting ambiguous characters as one cell.
+func Wrap(s string, w int, opts ...WrapOption) string {
+	return defaultMeasurer.Wrap(s, w, opts...)
+}
+
+// WrapFor wraps s to the width Columns returns for fd, counting ambiguous characters as one cell.
+func WrapFor(fd uintptr, s string, opts ...WrapOption) string {
+	return defaultMeasurer.WrapFor(fd, s, opts...)
+}